@@ -0,0 +1,78 @@
+package turtle
+
+import (
+	"image/color"
+	"math"
+)
+
+// How the Pen color is mixed with the pixels it paints.
+type BlendMode byte
+
+const (
+	BlendNormal   BlendMode = iota // Replace the pixel with the Pen color.
+	BlendAdd                       // Add the colors, glowing where lines overlap.
+	BlendMultiply                  // Multiply the colors, always darker.
+	BlendScreen                    // Invert, multiply and invert, always lighter.
+	BlendLighten                   // Keep the lighter of the two colors.
+	BlendDarken                    // Keep the darker of the two colors.
+)
+
+// Blend the source color s on the backdrop b.
+//
+// The separable blend mode mixes the colors where the backdrop is opaque,
+// then the result is composited with source over,
+// using the alpha of the source:
+//
+// https://www.w3.org/TR/compositing-1/#blending
+func blendColors(b, s color.Color, mode BlendMode) color.Color {
+	cb, ab := unpremultiply(b)
+	cs, as := unpremultiply(s)
+
+	var out [3]float64
+	for i := range out {
+		// mix where the backdrop is opaque
+		cr := (1-ab)*cs[i] + ab*blendChannel(cb[i], cs[i], mode)
+		// source over, premultiplied
+		out[i] = as*cr + ab*(1-as)*cb[i]
+	}
+	ao := as + ab*(1-as)
+
+	return color.RGBA64{
+		R: toChannel(out[0]),
+		G: toChannel(out[1]),
+		B: toChannel(out[2]),
+		A: toChannel(ao),
+	}
+}
+
+// Blend a single channel, with values in [0, 1].
+func blendChannel(b, s float64, mode BlendMode) float64 {
+	switch mode {
+	case BlendAdd:
+		return math.Min(1, b+s)
+	case BlendMultiply:
+		return b * s
+	case BlendScreen:
+		return b + s - b*s
+	case BlendLighten:
+		return math.Max(b, s)
+	case BlendDarken:
+		return math.Min(b, s)
+	}
+	return s
+}
+
+// Get the non premultiplied channels and the alpha of c, in [0, 1].
+func unpremultiply(c color.Color) ([3]float64, float64) {
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return [3]float64{}, 0
+	}
+	fa := float64(a)
+	return [3]float64{float64(r) / fa, float64(g) / fa, float64(b) / fa}, fa / 0xffff
+}
+
+// Convert a value in [0, 1] to a 16 bit channel.
+func toChannel(v float64) uint16 {
+	return uint16(math.Max(0, math.Min(1, v))*0xffff + 0.5)
+}
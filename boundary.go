@@ -0,0 +1,302 @@
+package turtle
+
+import (
+	"errors"
+	"math"
+)
+
+// What a TurtleDraw does when it reaches the border of the World.
+type BoundaryMode byte
+
+const (
+	BoundaryNone   BoundaryMode = iota // Keep going, the lines outside are lost.
+	BoundaryWrap                       // Come back from the opposite border, like on a torus.
+	BoundaryBounce                     // Reflect the heading, like a billiard ball.
+	BoundaryClamp                      // Stop on the border.
+	BoundaryStop                       // Stop on the border, and report ErrOutOfBounds.
+)
+
+// Error reported when a TurtleDraw with BoundaryStop reaches the border.
+var ErrOutOfBounds = errors.New("turtle: out of the World bounds")
+
+// Change what the turtle does when it reaches the border of the World.
+//
+// The border used by bounce, clamp and stop is the center of the outer pixels,
+// from (0, 0) to (Width-1, Height-1).
+// Wrap uses a torus of size (Width, Height).
+// A move that wraps or bounces more than Width+Height times
+// draws only its last Width+Height crossings.
+// Bouncing in a World 1 pixel wide (or high), the turtle slides along it.
+// An unbounded World has no border.
+func (td *TurtleDraw) SetBoundary(b BoundaryMode) {
+	td.do(func() { td.Boundary = b })
+}
+
+// Get the error reported by BoundaryStop, nil if the turtle is in bounds.
+func (td *TurtleDraw) Err() error {
+	return td.err
+}
+
+// Forget the error reported by BoundaryStop.
+func (td *TurtleDraw) ClearErr() {
+	td.err = nil
+}
+
+// Draw the move from (x0, y0) to the current position, applying the boundary.
+//
+// The position and the heading are changed as needed.
+func (td *TurtleDraw) travel(x0, y0 float64) {
+	// an empty World has no inside to keep the turtle in
+	if td.Boundary == BoundaryNone || td.W.chunks != nil || td.W.Width <= 0 || td.W.Height <= 0 {
+		td.segment(x0, y0, td.X, td.Y)
+		return
+	}
+	switch td.Boundary {
+	case BoundaryWrap, BoundaryBounce:
+		td.travelFold(x0, y0)
+	case BoundaryClamp, BoundaryStop:
+		td.travelClamp(x0, y0)
+	}
+}
+
+// Wrap or reflect the move on the borders.
+//
+// The move is a straight line in the unfolded plane, that the borders fold into the World:
+// the final pose is computed directly, and only the drawn part is split in segments.
+func (td *TurtleDraw) travelFold(x0, y0 float64) {
+	bounce := td.Boundary == BoundaryBounce
+	ax := newFoldAxis(x0, td.X, td.W.Width, bounce)
+	ay := newFoldAxis(y0, td.Y, td.W.Height, bounce)
+	dist := math.Hypot(ax.d, ay.d)
+
+	if !td.On {
+		td.stats.skip(dist, false)
+		td.stats.visit(x0, y0)
+	} else {
+		// a long move passes over the World many times,
+		// draw only its last Width+Height crossings
+		t := 0.0
+		rate := ax.rate() + ay.rate()
+		if n := float64(td.W.Width + td.W.Height); rate > n {
+			t = 1 - n/rate
+			td.stats.skip(t*dist, true)
+		}
+		for t < 1 {
+			tn := math.Min(1, math.Min(ax.next(t), ay.next(t)))
+			// the piece between two crossings is in a single cell
+			tm := (t + tn) / 2
+			cx, cy := ax.cell(ax.at(tm)), ay.cell(ay.at(tm))
+			td.segment(ax.fold(ax.at(t), cx), ay.fold(ay.at(t), cy),
+				ax.fold(ax.at(tn), cx), ay.fold(ay.at(tn), cy))
+			t = tn
+		}
+	}
+
+	td.X = ax.fold(ax.at(1), ax.endCell())
+	td.Y = ay.fold(ay.at(1), ay.endCell())
+	if !td.On {
+		td.stats.visit(td.X, td.Y)
+	}
+	if bounce {
+		td.reflect(ax.crossed(), ay.crossed())
+	}
+}
+
+// Reflect the heading nx times on the vertical borders and ny times on the horizontal ones.
+//
+// Each reflection is counted as a turn in the statistics.
+func (td *TurtleDraw) reflect(nx, ny float64) {
+	// the size of the turn is the same at each reflection on the same border
+	td.stats.turnRepeated(turnSize(180-2*td.Deg), nx)
+	td.stats.turnRepeated(turnSize(-2*td.Deg), ny)
+	if math.Mod(nx, 2) == 1 {
+		td.Deg = normDeg(180 - td.Deg)
+	}
+	if math.Mod(ny, 2) == 1 {
+		td.Deg = normDeg(-td.Deg)
+	}
+	td.snap()
+}
+
+// Get the size of the shortest rotation by deg degrees, in [0, 180].
+func turnSize(deg float64) float64 {
+	deg = normDeg(deg)
+	if deg > 180 {
+		return 360 - deg
+	}
+	return deg
+}
+
+// A coordinate of a move, folded into the World by the borders.
+//
+// The borders split the line in cells of length size:
+// the cell 0 is the World, the others are mirrored or shifted back into it.
+type foldAxis struct {
+	x0, d  float64 // The coordinate is x0 + t*d, with t in [0, 1].
+	size   float64 // Distance between the borders, 0 pins the coordinate on 0.
+	bounce bool    // Reflect on the borders, instead of wrapping.
+	below  bool    // The move started below the World, there are no borders below.
+	above  bool    // The move started above the World, there are no borders above.
+}
+
+// Create the foldAxis of a move from x0 to x1, in a World n pixels wide.
+func newFoldAxis(x0, x1 float64, n int, bounce bool) foldAxis {
+	a := foldAxis{bounce: bounce}
+	if bounce {
+		a.size = float64(n - 1)
+		a.below = x0 < 0
+		a.above = x0 > a.size
+	} else {
+		// start from inside the torus
+		a.size = float64(n)
+		s := math.Floor(x0/a.size) * a.size
+		x0, x1 = x0-s, x1-s
+	}
+	a.x0, a.d = x0, x1-x0
+	return a
+}
+
+// Get the unfolded coordinate at t.
+func (a foldAxis) at(t float64) float64 {
+	return a.x0 + t*a.d
+}
+
+// Get the cell of the unfolded coordinate x.
+func (a foldAxis) cell(x float64) float64 {
+	return math.Floor(x / a.size)
+}
+
+// Get the cell at the end of the move: a border reached exactly is not crossed.
+func (a foldAxis) endCell() float64 {
+	x1 := a.at(1)
+	switch {
+	case a.d > 0:
+		return math.Ceil(x1/a.size) - 1
+	case a.d < 0:
+		return math.Floor(x1 / a.size)
+	}
+	return a.cell(x1)
+}
+
+// Fold the unfolded coordinate x, in the cell c, into the World.
+func (a foldAxis) fold(x, c float64) float64 {
+	switch {
+	case a.size == 0:
+		return 0
+	case a.below && c < 0, a.above && c > 0:
+		return x
+	case !a.bounce || math.Mod(c, 2) == 0:
+		return x - c*a.size
+	}
+	return (c+1)*a.size - x
+}
+
+// Get the number of borders crossed per unit of t.
+func (a foldAxis) rate() float64 {
+	if a.size == 0 {
+		return 0
+	}
+	return math.Abs(a.d) / a.size
+}
+
+// Get the first t after t where the move crosses a border, 2 if there is none.
+func (a foldAxis) next(t float64) float64 {
+	if a.size == 0 || a.d == 0 {
+		return 2
+	}
+	// the index k of the border, and its direction
+	x := a.at(t)
+	var k, step float64
+	if a.d > 0 {
+		if a.above {
+			return 2
+		}
+		k, step = math.Floor(x/a.size)+1, 1
+		if a.below {
+			k = math.Max(k, 1)
+		}
+	} else {
+		if a.below {
+			return 2
+		}
+		k, step = math.Ceil(x/a.size)-1, -1
+		if a.above {
+			k = math.Min(k, 0)
+		}
+	}
+	tk := (k*a.size - a.x0) / a.d
+	for tk <= t {
+		k += step
+		tk = (k*a.size - a.x0) / a.d
+	}
+	return tk
+}
+
+// Count the borders crossed by the whole move.
+func (a foldAxis) crossed() float64 {
+	if a.size == 0 || a.d == 0 {
+		return 0
+	}
+	c := a.endCell()
+	switch {
+	case a.below:
+		return math.Max(c, 0)
+	case a.above:
+		return math.Max(-c, 0)
+	}
+	return math.Abs(c)
+}
+
+// Stop the move on the border.
+func (td *TurtleDraw) travelClamp(x0, y0 float64) {
+	w := float64(td.W.Width - 1)
+	h := float64(td.W.Height - 1)
+	x1, y1 := td.X, td.Y
+	dx, dy := x1-x0, y1-y0
+
+	t := 1.0
+	if tx, ok := edgeHit(x0, dx, 0, w); ok && tx < t {
+		t = tx
+	}
+	if ty, ok := edgeHit(y0, dy, 0, h); ok && ty < t {
+		t = ty
+	}
+	if t < 1 {
+		x1, y1 = x0+t*dx, y0+t*dy
+		if td.Boundary == BoundaryStop {
+			td.err = ErrOutOfBounds
+		}
+	}
+
+	// the move started outside: just bring the turtle back
+	x1 = math.Max(0, math.Min(w, x1))
+	y1 = math.Max(0, math.Min(h, y1))
+
+	td.segment(x0, y0, x1, y1)
+	td.X, td.Y = x1, y1
+}
+
+// Find when the coordinate x0 + t*d leaves the interval [lo, hi],
+// moving from inside, with t in [0, 1).
+func edgeHit(x0, d, lo, hi float64) (float64, bool) {
+	var t float64
+	switch {
+	case d > 0 && x0 <= hi:
+		t = (hi - x0) / d
+	case d < 0 && x0 >= lo:
+		t = (lo - x0) / d
+	default:
+		return 0, false
+	}
+	// on the border and moving out: this is the hit
+	// on the border after a previous hit and moving in: no hit
+	return t, t < 1
+}
+
+// Draw a line if the Pen is On, and record it in the statistics.
+func (td *TurtleDraw) segment(x0, y0, x1, y1 float64) {
+	td.stats.path(x0, y0, x1, y1, td.On)
+	if td.On {
+		td.drawLine(Line{x0, y0, x1, y1, td.Pen.Style(), td.Layer})
+	}
+}
@@ -0,0 +1,156 @@
+package turtle_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Create a TurtleDraw with a thin pen in (x, y), with the boundary b.
+func newBounded(w *turtle.World, b turtle.BoundaryMode, x, y float64) *turtle.TurtleDraw {
+	td := turtle.NewTurtleDraw(w)
+	td.SetSize(1)
+	td.SetPos(x, y)
+	td.SetBoundary(b)
+	td.ResetStats()
+	return td
+}
+
+// Check if the pixel in cartesian coordinates (x, y) was drawn.
+func drawn(w *turtle.World, x, y int) bool {
+	r, g, b, _ := w.Image.At(x, w.Height-y-1).RGBA()
+	r0, g0, b0, _ := turtle.SoftBlack.RGBA()
+	return r != r0 || g != g0 || b != b0
+}
+
+func TestBoundaryLongMove(t *testing.T) {
+	for _, b := range []turtle.BoundaryMode{turtle.BoundaryWrap, turtle.BoundaryBounce} {
+		for _, on := range []bool{false, true} {
+			w := turtle.NewWorld(50, 50)
+			td := newBounded(w, b, 10, 20)
+			if on {
+				td.PenDown()
+			}
+			td.Left(30)
+			td.Forward(1e8)
+			if td.X < 0 || td.X > 50 || td.Y < 0 || td.Y > 50 {
+				t.Errorf("mode %d pen %t: ended in (%v, %v), outside the World", b, on, td.X, td.Y)
+			}
+			if s := td.Stats(); math.Abs(s.Distance-1e8) > 1e-6 {
+				t.Errorf("mode %d pen %t: travelled %v, want 1e8", b, on, s.Distance)
+			}
+			w.Close()
+		}
+	}
+}
+
+func TestBoundaryWrap(t *testing.T) {
+	w := turtle.NewWorld(20, 10)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryWrap, 15, 5)
+	td.PenDown()
+	td.Forward(10)
+	if td.X != 5 || td.Y != 5 {
+		t.Errorf("got (%v, %v), want (5, 5)", td.X, td.Y)
+	}
+	for _, x := range []int{15, 19, 0, 5} {
+		if !drawn(w, x, 5) {
+			t.Errorf("pixel (%d, 5) not drawn", x)
+		}
+	}
+	if drawn(w, 10, 5) {
+		t.Error("pixel (10, 5) drawn across the World")
+	}
+}
+
+func TestBoundaryBounce(t *testing.T) {
+	w := turtle.NewWorld(20, 20)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryBounce, 10, 10)
+	td.PenDown()
+	// reflect on the right border at 19, then on the left one at 0
+	td.Forward(9 + 19 + 8)
+	if td.X != 8 || td.Y != 10 || td.Deg != 0 {
+		t.Errorf("got (%v, %v) heading %v, want (8, 10) heading 0", td.X, td.Y, td.Deg)
+	}
+	if s := td.Stats(); s.Turns != 2 || s.Turned != 360 {
+		t.Errorf("got %d turns of %v degrees, want 2 of 360", s.Turns, s.Turned)
+	}
+	if !drawn(w, 19, 10) || !drawn(w, 0, 10) {
+		t.Error("the borders were not reached")
+	}
+
+	td.SetPos(5, 10)
+	td.SetHeading(45)
+	td.Forward(13)
+	if td.Deg != 315 {
+		t.Errorf("got heading %v after the top border, want 315", td.Deg)
+	}
+}
+
+func TestBoundaryBounceThin(t *testing.T) {
+	w := turtle.NewWorld(1, 50)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryBounce, 0, 10)
+	td.PenDown()
+	td.Left(60)
+	td.Forward(1e8)
+	if td.X != 0 || td.Y < 0 || td.Y > 49 {
+		t.Errorf("got (%v, %v), want on the column x = 0", td.X, td.Y)
+	}
+}
+
+func TestBoundaryClamp(t *testing.T) {
+	w := turtle.NewWorld(20, 20)
+	defer w.Close()
+	for _, b := range []turtle.BoundaryMode{turtle.BoundaryClamp, turtle.BoundaryStop} {
+		td := newBounded(w, b, 10, 10)
+		td.Forward(100)
+		if td.X != 19 || td.Y != 10 {
+			t.Errorf("mode %d: got (%v, %v), want (19, 10)", b, td.X, td.Y)
+		}
+		if b == turtle.BoundaryStop && td.Err() != turtle.ErrOutOfBounds {
+			t.Errorf("mode %d: got error %v, want ErrOutOfBounds", b, td.Err())
+		}
+		if b == turtle.BoundaryClamp && td.Err() != nil {
+			t.Errorf("mode %d: got error %v, want nil", b, td.Err())
+		}
+	}
+}
+
+func TestBoundaryNone(t *testing.T) {
+	w := turtle.NewWorld(20, 20)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 10, 10)
+	td.Forward(100)
+	if td.X != 110 {
+		t.Errorf("got x %v, want 110", td.X)
+	}
+}
+
+func TestBoundaryStopInstruction(t *testing.T) {
+	w := turtle.NewWorld(20, 20)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryStop, 10, 10)
+
+	in := make(chan turtle.Instruction)
+	go fixed(
+		turtle.Instruction{Cmd: turtle.CmdForward, Amount: 5},
+		turtle.Instruction{Cmd: turtle.CmdForward, Amount: 100},
+		turtle.Instruction{Cmd: turtle.CmdLeft, Amount: 90},
+	)(in)
+	n, err := turtle.Run(context.Background(), td, in)
+	if n != 1 || err != turtle.ErrOutOfBounds {
+		t.Errorf("got %d instructions and %v, want 1 and ErrOutOfBounds", n, err)
+	}
+
+	// only the instruction that reached the border fails
+	if err := td.DoInstruction(turtle.Instruction{Cmd: turtle.CmdLeft, Amount: 90}); err != nil {
+		t.Errorf("got %v turning after the border, want nil", err)
+	}
+	if td.Err() != turtle.ErrOutOfBounds {
+		t.Errorf("got %v from Err, want ErrOutOfBounds", td.Err())
+	}
+}
@@ -0,0 +1,114 @@
+package turtle
+
+import "math"
+
+// Projects 3D segments on the image plane of a World.
+type Camera interface {
+	// Project the segment from a to b,
+	// clipping the part that can not be seen.
+	// Returns the ends in cartesian image coordinates, their depth,
+	// and false if nothing is visible.
+	ProjectSegment(a, b Vec3) (p0, p1 Point, d0, d1 float64, ok bool)
+}
+
+// The reference frame of a camera.
+type cameraFrame struct {
+	eye     Vec3
+	r, u, f Vec3 // Right, up and forward unit vectors.
+}
+
+// Build the frame of a camera in eye, looking at target.
+func newCameraFrame(eye, target, up Vec3) cameraFrame {
+	f := target.Sub(eye).Normalize()
+	r := f.Cross(up).Normalize()
+	u := r.Cross(f)
+	return cameraFrame{eye, r, u, f}
+}
+
+// Get the coordinates of p in the camera frame: X right, Y up, Z depth.
+func (cf cameraFrame) view(p Vec3) Vec3 {
+	d := p.Sub(cf.eye)
+	return Vec3{d.Dot(cf.r), d.Dot(cf.u), d.Dot(cf.f)}
+}
+
+// An orthographic Camera: parallel lines stay parallel.
+type OrthoCamera struct {
+	cameraFrame
+	Scale            float64 // Pixels per unit.
+	CenterX, CenterY float64 // Where the target ends up in the image.
+}
+
+var _ Camera = &OrthoCamera{}
+
+// Create an orthographic Camera in eye, looking at target,
+// placed in the center of an image of size (width, height).
+func NewOrthoCamera(eye, target, up Vec3, scale float64, width, height int) *OrthoCamera {
+	return &OrthoCamera{
+		cameraFrame: newCameraFrame(eye, target, up),
+		Scale:       scale,
+		CenterX:     float64(width) / 2,
+		CenterY:     float64(height) / 2,
+	}
+}
+
+// Project the segment, nothing is clipped.
+//
+// Implements: Camera
+func (c *OrthoCamera) ProjectSegment(a, b Vec3) (Point, Point, float64, float64, bool) {
+	va := c.view(a)
+	vb := c.view(b)
+	p0 := Point{c.CenterX + c.Scale*va.X, c.CenterY + c.Scale*va.Y}
+	p1 := Point{c.CenterX + c.Scale*vb.X, c.CenterY + c.Scale*vb.Y}
+	return p0, p1, va.Z, vb.Z, true
+}
+
+// A perspective Camera: far things look small.
+type PerspectiveCamera struct {
+	cameraFrame
+	Focal            float64 // Distance of the image plane, in pixels.
+	Near             float64 // The parts closer than this are clipped.
+	CenterX, CenterY float64 // Where the target ends up in the image.
+}
+
+var _ Camera = &PerspectiveCamera{}
+
+// Create a perspective Camera in eye, looking at target,
+// with a vertical field of view of fov degrees,
+// placed in the center of an image of size (width, height).
+func NewPerspectiveCamera(eye, target, up Vec3, fov float64, width, height int) *PerspectiveCamera {
+	return &PerspectiveCamera{
+		cameraFrame: newCameraFrame(eye, target, up),
+		Focal:       float64(height) / 2 / math.Tan(Deg2rad(fov)/2),
+		Near:        1e-3,
+		CenterX:     float64(width) / 2,
+		CenterY:     float64(height) / 2,
+	}
+}
+
+// Project the segment, clipping the part behind the Near plane.
+//
+// Implements: Camera
+func (c *PerspectiveCamera) ProjectSegment(a, b Vec3) (Point, Point, float64, float64, bool) {
+	va := c.view(a)
+	vb := c.view(b)
+
+	// clip to the near plane
+	if va.Z < c.Near && vb.Z < c.Near {
+		return Point{}, Point{}, 0, 0, false
+	}
+	if va.Z < c.Near {
+		va = clipNear(vb, va, c.Near)
+	} else if vb.Z < c.Near {
+		vb = clipNear(va, vb, c.Near)
+	}
+
+	p0 := Point{c.CenterX + c.Focal*va.X/va.Z, c.CenterY + c.Focal*va.Y/va.Z}
+	p1 := Point{c.CenterX + c.Focal*vb.X/vb.Z, c.CenterY + c.Focal*vb.Y/vb.Z}
+	return p0, p1, va.Z, vb.Z, true
+}
+
+// Find where the segment from the visible in to the hidden out crosses the near plane.
+func clipNear(in, out Vec3, near float64) Vec3 {
+	t := (in.Z - near) / (in.Z - out.Z)
+	return in.Add(out.Sub(in).Scale(t))
+}
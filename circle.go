@@ -0,0 +1,66 @@
+package turtle
+
+import "math"
+
+// Move along an arc of circle of the given radius, like Python turtle.circle.
+//
+// The center is radius units to the left of the turtle,
+// a negative radius puts it on the right and the turtle turns clockwise.
+// The extent is the angle of the arc, in the current angle units,
+// 0 draws the full circle, a negative extent moves backwards.
+//
+// The arc is approximated by a polygon, within curveTolerance from the circle.
+func (t *Turtle) Circle(radius, extent float64) {
+	steps, l, w := t.circleSteps(radius, extent)
+	t.Left(w / 2)
+	for i := 0; i < steps; i++ {
+		t.Forward(l)
+		t.Left(w)
+	}
+	t.Left(-w / 2)
+}
+
+// Draw an arc of circle of the given radius, like Python turtle.circle.
+//
+// The center is radius units to the left of the turtle,
+// a negative radius puts it on the right and the turtle turns clockwise.
+// The extent is the angle of the arc, in the current angle units,
+// 0 draws the full circle, a negative extent moves backwards.
+func (td *TurtleDraw) Circle(radius, extent float64) {
+	td.do(func() {
+		steps, l, w := td.circleSteps(radius, extent)
+		td.Left(w / 2)
+		for i := 0; i < steps; i++ {
+			td.Forward(l)
+			td.Left(w)
+		}
+		td.Left(-w / 2)
+	})
+}
+
+// Split an arc in steps, each a line of length l followed by a turn of w,
+// in the current angle units.
+func (t *Turtle) circleSteps(radius, extent float64) (steps int, l, w float64) {
+	full := t.FullCircle()
+	if extent == 0 {
+		extent = full
+	}
+
+	// the largest angle whose chord stays within the tolerance from the arc
+	maxStep := math.Pi
+	if r := math.Abs(radius); r > curveTolerance {
+		maxStep = 2 * math.Acos(1-curveTolerance/r)
+	}
+	arc := math.Abs(extent) / full * 2 * math.Pi
+	steps = int(math.Max(1, math.Ceil(arc/maxStep)))
+
+	w = extent / float64(steps)
+	l = 2 * radius * math.Sin(arc/float64(steps)/2)
+	if extent < 0 {
+		l = -l
+	}
+	if radius < 0 {
+		l, w = -l, -w
+	}
+	return steps, l, w
+}
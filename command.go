@@ -1,5 +1,16 @@
 package turtle
 
+import (
+	"errors"
+	"image/color"
+)
+
+// Errors returned when executing an Instruction.
+var (
+	ErrUnknownCmd     = errors.New("turtle: unknown command")
+	ErrUnsupportedCmd = errors.New("turtle: unsupported instruction")
+)
+
 // Possible commands to send inside an Instruction.
 type CmdType byte
 
@@ -8,10 +19,35 @@ const (
 	CmdBackward
 	CmdLeft
 	CmdRight
+
+	// Rotations in 3D, ignored by the 2D turtles.
+	CmdPitchUp
+	CmdPitchDown
+	CmdRollLeft
+	CmdRollRight
+
+	// Pen, position and stack.
+	// The turtles without a Pen ignore the Pen commands,
+	// the turtles that can not execute the others return ErrUnsupportedCmd.
+	CmdPenUp
+	CmdPenDown
+	CmdSetColor   // Change the Pen color to Color.
+	CmdSetSize    // Change the Pen size to Amount.
+	CmdSetPos     // Teleport to (X, Y).
+	CmdSetHeading // Orient towards Amount.
+	CmdPush
+	CmdPop
+	CmdCircle // Draw an arc of radius Amount and angle Extent.
 )
 
 // An action for the turtle.
+//
+// Amount is the distance, angle, size or radius, depending on the command.
+// The other fields are used only by the commands that need them.
 type Instruction struct {
 	Cmd    CmdType
 	Amount float64
+	Extent float64     // Angle of the arc of CmdCircle, 0 for a full circle.
+	X, Y   float64     // Position of CmdSetPos.
+	Color  color.Color // Color of CmdSetColor.
 }
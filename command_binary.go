@@ -0,0 +1,420 @@
+package turtle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Error returned when decoding binary instructions that are not valid.
+var ErrBadEncoding = errors.New("turtle: malformed binary instructions")
+
+// Default step of the quantized values.
+//
+// A power of two, so that the multiples with a short binary expansion,
+// like most segment lengths and angles, are exact.
+const DefaultQuantum = 1.0 / 1024
+
+// Header of the binary format: magic string and version.
+const binaryMagic = "TRTL\x01"
+
+// Flags in the command byte of the binary format, the command is in the low bits.
+const (
+	flagRaw    = 1 << 5 // Values written as float64 bits instead of quantized.
+	flagSame   = 1 << 6 // Values equal to the previous instruction with the same command.
+	flagRepeat = 1 << 7 // Followed by the number of extra copies of the instruction.
+	cmdMask    = flagRaw - 1
+)
+
+// Encode instructions in a compact binary format.
+//
+// The stream starts with a header that holds the quantum.
+// Each instruction is a command byte followed by its values:
+// a value that is a multiple of the quantum is written as a varint of the multiple,
+// the others as the float64 bits, so the encoding is lossless.
+// The values equal to the previous ones of the same command are omitted,
+// and consecutive equal instructions are written once with a count.
+//
+// A level 20 dragon, whose segments all have the same length,
+// takes little more than one byte per instruction.
+type Encoder struct {
+	w       *bufio.Writer
+	quantum float64
+	started bool // Header written.
+
+	pending []byte   // Encoded instruction waiting for its copies.
+	count   uint64   // Extra copies of the pending instruction.
+	last    [][]byte // Values of the last instruction of each command.
+}
+
+// Create a new Encoder writing on w, with the DefaultQuantum.
+func NewEncoder(w io.Writer) *Encoder {
+	return NewEncoderWithQuantum(w, DefaultQuantum)
+}
+
+// Create a new Encoder writing on w, with the given quantum.
+//
+// A quantum that is not positive makes all the values raw float64.
+func NewEncoderWithQuantum(w io.Writer, quantum float64) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w), quantum: quantum, last: make([][]byte, len(cmdCodes))}
+}
+
+// Encode an instruction.
+//
+// The instruction is buffered, call Flush at the end.
+func (e *Encoder) Encode(i Instruction) error {
+	b, err := e.encodeValues(i)
+	if err != nil {
+		return err
+	}
+	if e.pending != nil && bytes.Equal(b, e.pending) {
+		e.count++
+		return nil
+	}
+	if err := e.writePending(); err != nil {
+		return err
+	}
+	e.pending = b
+	return nil
+}
+
+// Encode all the instructions received on the channel, until it is closed.
+//
+// Returns the number of instructions encoded.
+// The encoder is flushed at the end.
+func (e *Encoder) EncodeAll(instructions <-chan Instruction) (int, error) {
+	n := 0
+	for i := range instructions {
+		if err := e.Encode(i); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, e.Flush()
+}
+
+// Write the buffered instructions to the underlying writer.
+//
+// The header is written even if no instruction was encoded.
+func (e *Encoder) Flush() error {
+	if err := e.writePending(); err != nil {
+		return err
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+// Write the header, if it was not written yet.
+func (e *Encoder) writeHeader() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+	var b [len(binaryMagic) + 8]byte
+	copy(b[:], binaryMagic)
+	binary.LittleEndian.PutUint64(b[len(binaryMagic):], math.Float64bits(e.quantum))
+	_, err := e.w.Write(b[:])
+	return err
+}
+
+// Write the pending instruction with the count of its copies.
+func (e *Encoder) writePending() error {
+	if e.pending == nil {
+		return nil
+	}
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	b := e.pending
+	cmd := b[0] & cmdMask
+	if bytes.Equal(b, e.last[cmd]) {
+		b = []byte{b[0] | flagSame}
+	} else {
+		e.last[cmd] = b
+	}
+	head := b[0]
+	if e.count > 0 {
+		head |= flagRepeat
+	}
+	if err := e.w.WriteByte(head); err != nil {
+		return err
+	}
+	if e.count > 0 {
+		var c [binary.MaxVarintLen64]byte
+		if _, err := e.w.Write(c[:binary.PutUvarint(c[:], e.count)]); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.Write(b[1:])
+	e.pending, e.count = nil, 0
+	return err
+}
+
+// Encode the command byte and the values of an instruction.
+func (e *Encoder) encodeValues(i Instruction) ([]byte, error) {
+	if int(i.Cmd) >= len(cmdCodes) {
+		return nil, ErrUnknownCmd
+	}
+	b := []byte{byte(i.Cmd)}
+
+	var values []float64
+	switch i.Cmd {
+	case CmdPenUp, CmdPenDown, CmdPush, CmdPop:
+		return b, nil
+	case CmdSetColor:
+		if i.Color == nil {
+			return append(b, 0), nil
+		}
+		n := color.NRGBAModel.Convert(i.Color).(color.NRGBA)
+		return append(b, 1, n.R, n.G, n.B, n.A), nil
+	case CmdSetPos:
+		values = []float64{i.X, i.Y}
+	case CmdCircle:
+		values = []float64{i.Amount, i.Extent}
+	default:
+		values = []float64{i.Amount}
+	}
+
+	multiples := make([]int64, len(values))
+	for k, v := range values {
+		m, ok := e.quantize(v)
+		if !ok {
+			b[0] |= flagRaw
+			for _, v := range values {
+				var f [8]byte
+				binary.LittleEndian.PutUint64(f[:], math.Float64bits(v))
+				b = append(b, f[:]...)
+			}
+			return b, nil
+		}
+		multiples[k] = m
+	}
+	var c [binary.MaxVarintLen64]byte
+	for _, m := range multiples {
+		b = append(b, c[:binary.PutVarint(c[:], m)]...)
+	}
+	return b, nil
+}
+
+// Get the multiple of the quantum equal to v, false if there is none.
+func (e *Encoder) quantize(v float64) (int64, bool) {
+	if !(e.quantum > 0) {
+		return 0, false
+	}
+	m := math.Round(v / e.quantum)
+	if math.Abs(m) > 1<<53 {
+		return 0, false
+	}
+	// the bits of the decoded value must match, -0 is written raw
+	n := int64(m)
+	if math.Float64bits(float64(n)*e.quantum) != math.Float64bits(v) {
+		return 0, false
+	}
+	return n, true
+}
+
+// Decode instructions written by an Encoder.
+type Decoder struct {
+	r       *bufio.Reader
+	quantum float64
+	started bool // Header read.
+
+	repeat Instruction   // Instruction to send again.
+	count  uint64        // Copies of it left.
+	last   []Instruction // Last instruction of each command.
+	seen   []bool        // Commands decoded at least once.
+}
+
+// Create a new Decoder, reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:    bufio.NewReader(r),
+		last: make([]Instruction, len(cmdCodes)),
+		seen: make([]bool, len(cmdCodes)),
+	}
+}
+
+// Decode the next instruction.
+//
+// Returns io.EOF at the end of the input,
+// an error wrapping ErrBadEncoding for malformed or truncated data,
+// and the other errors of the reader as they are.
+func (d *Decoder) Decode() (Instruction, error) {
+	if err := d.readHeader(); err != nil {
+		return Instruction{}, err
+	}
+	if d.count > 0 {
+		d.count--
+		return d.repeat, nil
+	}
+
+	head, err := d.r.ReadByte()
+	if err != nil {
+		return Instruction{}, err
+	}
+	cmd := CmdType(head & cmdMask)
+	if int(cmd) >= len(cmdCodes) {
+		return Instruction{}, fmt.Errorf("%w: unknown command %d", ErrBadEncoding, cmd)
+	}
+	if head&flagRepeat != 0 {
+		if d.count, err = d.readUvarint(); err != nil {
+			return Instruction{}, err
+		}
+	}
+
+	var i Instruction
+	if head&flagSame != 0 {
+		if !d.seen[cmd] {
+			return Instruction{}, fmt.Errorf("%w: no previous values for %q", ErrBadEncoding, cmdCodes[cmd].code)
+		}
+		i = d.last[cmd]
+	} else {
+		if i, err = d.decodeValues(cmd, head&flagRaw != 0); err != nil {
+			return Instruction{}, err
+		}
+		d.last[cmd], d.seen[cmd] = i, true
+	}
+	d.repeat = i
+	return i, nil
+}
+
+// Decode all the instructions and send them on the channel,
+// that is closed at the end, like the fractal generators do.
+func (d *Decoder) DecodeAll(instructions chan<- Instruction) error {
+	defer close(instructions)
+	for {
+		i, err := d.Decode()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		instructions <- i
+	}
+}
+
+// Read the header, if it was not read yet.
+func (d *Decoder) readHeader() error {
+	if d.started {
+		return nil
+	}
+	var b [len(binaryMagic) + 8]byte
+	if _, err := io.ReadFull(d.r, b[:]); err != nil {
+		if isEOF(err) {
+			return fmt.Errorf("%w: missing header", ErrBadEncoding)
+		}
+		return err
+	}
+	// the last byte of the magic string is the version
+	v := len(binaryMagic) - 1
+	if string(b[:v]) != binaryMagic[:v] {
+		return fmt.Errorf("%w: bad header", ErrBadEncoding)
+	}
+	if b[v] != binaryMagic[v] {
+		return fmt.Errorf("%w: unsupported version %d", ErrBadEncoding, b[v])
+	}
+	d.quantum = math.Float64frombits(binary.LittleEndian.Uint64(b[len(binaryMagic):]))
+	d.started = true
+	return nil
+}
+
+// Decode the values of an instruction.
+func (d *Decoder) decodeValues(cmd CmdType, raw bool) (Instruction, error) {
+	i := Instruction{Cmd: cmd}
+	switch cmd {
+	case CmdPenUp, CmdPenDown, CmdPush, CmdPop:
+		return i, nil
+	case CmdSetColor:
+		tag, err := d.r.ReadByte()
+		if err != nil {
+			return i, truncated(err)
+		}
+		if tag == 0 {
+			return i, nil
+		}
+		var c [4]byte
+		if _, err := io.ReadFull(d.r, c[:]); err != nil {
+			return i, truncated(err)
+		}
+		i.Color = color.NRGBA{c[0], c[1], c[2], c[3]}
+		return i, nil
+	}
+
+	values := make([]float64, cmdCodes[cmd].values)
+	for k := range values {
+		if raw {
+			var b [8]byte
+			if _, err := io.ReadFull(d.r, b[:]); err != nil {
+				return i, truncated(err)
+			}
+			values[k] = math.Float64frombits(binary.LittleEndian.Uint64(b[:]))
+			continue
+		}
+		m, err := d.readVarint()
+		if err != nil {
+			return i, err
+		}
+		values[k] = float64(m) * d.quantum
+	}
+	switch cmd {
+	case CmdSetPos:
+		i.X, i.Y = values[0], values[1]
+	case CmdCircle:
+		i.Amount, i.Extent = values[0], values[1]
+	default:
+		i.Amount = values[0]
+	}
+	return i, nil
+}
+
+// Read a varint written by binary.PutUvarint.
+func (d *Decoder) readUvarint() (uint64, error) {
+	var x uint64
+	var shift uint
+	for k := 0; k < binary.MaxVarintLen64; k++ {
+		b, err := d.r.ReadByte()
+		if err != nil {
+			return 0, truncated(err)
+		}
+		if b < 0x80 {
+			if k == binary.MaxVarintLen64-1 && b > 1 {
+				break
+			}
+			return x | uint64(b)<<shift, nil
+		}
+		x |= uint64(b&0x7f) << shift
+		shift += 7
+	}
+	return 0, fmt.Errorf("%w: varint overflows 64 bits", ErrBadEncoding)
+}
+
+// Read a zigzag varint written by binary.PutVarint.
+func (d *Decoder) readVarint() (int64, error) {
+	ux, err := d.readUvarint()
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	return x, err
+}
+
+// Report the end of the input in the middle of an instruction as malformed data.
+func truncated(err error) error {
+	if isEOF(err) {
+		return fmt.Errorf("%w: truncated instruction", ErrBadEncoding)
+	}
+	return err
+}
+
+// Check if the error is the end of the input, expected or not.
+func isEOF(err error) bool {
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
@@ -0,0 +1,100 @@
+package turtle_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Encode the instructions in the binary format.
+func encode(t *testing.T, is []turtle.Instruction) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := turtle.NewEncoder(&buf)
+	for _, i := range is {
+		if err := enc.Encode(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Decode all the instructions, until the first error.
+func decode(b []byte) ([]turtle.Instruction, error) {
+	var is []turtle.Instruction
+	dec := turtle.NewDecoder(bytes.NewReader(b))
+	for {
+		i, err := dec.Decode()
+		if err == io.EOF {
+			return is, nil
+		}
+		if err != nil {
+			return is, err
+		}
+		is = append(is, i)
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	want := mixedInstructions()
+	got, err := decode(encode(t, want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkInstructions(t, got, want)
+}
+
+func TestBinaryTruncated(t *testing.T) {
+	want := mixedInstructions()
+	b := encode(t, want)
+	for n := 0; n < len(b); n++ {
+		got, err := decode(b[:n])
+		// a cut between two instructions is a shorter stream
+		if err != nil && !errors.Is(err, turtle.ErrBadEncoding) {
+			t.Errorf("cut at %d: got %v, want ErrBadEncoding", n, err)
+		}
+		if len(got) > len(want) {
+			t.Fatalf("cut at %d: got %d instructions, more than the %d encoded", n, len(got), len(want))
+		}
+		for k := range got {
+			if !sameInstruction(got[k], want[k]) {
+				t.Errorf("cut at %d, instruction %d: got %v, want %v", n, k, got[k], want[k])
+			}
+		}
+	}
+}
+
+func TestBinaryMalformed(t *testing.T) {
+	header := encode(t, nil)
+	version := append([]byte{}, header...)
+	version[4] = 0x02
+	magic := append([]byte{}, header...)
+	magic[0] = 'X'
+	cases := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("TRTL\x02"),
+		"version":    version,
+		"magic":      magic,
+		"command":    append(append([]byte{}, header...), 0x1f),
+		"no values":  append(append([]byte{}, header...), 0x40),
+		"overflow":   append(append([]byte{}, header...), 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f),
+		"repetition": append(append([]byte{}, header...), 0x8f, 0xff),
+	}
+	for name, b := range cases {
+		if _, err := decode(b); !errors.Is(err, turtle.ErrBadEncoding) {
+			t.Errorf("%s: got %v, want ErrBadEncoding", name, err)
+		}
+	}
+
+	// a full header with another version is not a truncated stream
+	if _, err := decode(version); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("version: got %v, want an unsupported version", err)
+	}
+}
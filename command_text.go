@@ -0,0 +1,252 @@
+package turtle
+
+import (
+	"bufio"
+	"encoding"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Error returned when parsing an Instruction that is not valid.
+var ErrBadInstruction = errors.New("turtle: malformed instruction")
+
+// Code of each command in the text format, with the number of values that follow it.
+var cmdCodes = []struct {
+	code   string
+	values int
+}{
+	CmdForward:    {"F", 1},
+	CmdBackward:   {"B", 1},
+	CmdLeft:       {"L", 1},
+	CmdRight:      {"R", 1},
+	CmdPitchUp:    {"^", 1},
+	CmdPitchDown:  {"&", 1},
+	CmdRollLeft:   {"\\", 1},
+	CmdRollRight:  {"/", 1},
+	CmdPenUp:      {"U", 0},
+	CmdPenDown:    {"D", 0},
+	CmdSetColor:   {"C", 1},
+	CmdSetSize:    {"S", 1},
+	CmdSetPos:     {"P", 2},
+	CmdSetHeading: {"H", 1},
+	CmdPush:       {"[", 0},
+	CmdPop:        {"]", 0},
+	CmdCircle:     {"O", 2},
+}
+
+var (
+	_ encoding.TextMarshaler   = Instruction{}
+	_ encoding.TextUnmarshaler = &Instruction{}
+)
+
+// Write the instruction as text, like "F 10" or "P 100 250".
+//
+// The color of CmdSetColor is written as #rrggbbaa, not premultiplied,
+// or "none" for a nil color.
+//
+// Implements: encoding.TextMarshaler
+func (i Instruction) MarshalText() ([]byte, error) {
+	if int(i.Cmd) >= len(cmdCodes) {
+		return nil, ErrUnknownCmd
+	}
+	b := []byte(cmdCodes[i.Cmd].code)
+	appendFloat := func(f float64) {
+		b = append(b, ' ')
+		b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	}
+	switch i.Cmd {
+	case CmdPenUp, CmdPenDown, CmdPush, CmdPop:
+		// no values
+	case CmdSetColor:
+		b = append(b, ' ')
+		b = append(b, formatColor(i.Color)...)
+	case CmdSetPos:
+		appendFloat(i.X)
+		appendFloat(i.Y)
+	case CmdCircle:
+		appendFloat(i.Amount)
+		appendFloat(i.Extent)
+	default:
+		appendFloat(i.Amount)
+	}
+	return b, nil
+}
+
+// Parse an instruction written by MarshalText.
+//
+// Implements: encoding.TextUnmarshaler
+func (i *Instruction) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) == 0 {
+		return fmt.Errorf("%w: empty", ErrBadInstruction)
+	}
+	cmd := -1
+	for c, cc := range cmdCodes {
+		if cc.code == fields[0] {
+			cmd = c
+			break
+		}
+	}
+	if cmd < 0 {
+		return fmt.Errorf("%w: unknown command %q", ErrBadInstruction, fields[0])
+	}
+	if len(fields)-1 != cmdCodes[cmd].values {
+		return fmt.Errorf("%w: wrong number of values for %q", ErrBadInstruction, fields[0])
+	}
+
+	parsed := Instruction{Cmd: CmdType(cmd)}
+	if parsed.Cmd == CmdSetColor {
+		c, err := parseColor(fields[1])
+		if err != nil {
+			return err
+		}
+		parsed.Color = c
+		*i = parsed
+		return nil
+	}
+
+	values := make([]float64, len(fields)-1)
+	for k, f := range fields[1:] {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return fmt.Errorf("%w: bad number %q", ErrBadInstruction, f)
+		}
+		values[k] = v
+	}
+	switch parsed.Cmd {
+	case CmdSetPos:
+		parsed.X, parsed.Y = values[0], values[1]
+	case CmdCircle:
+		parsed.Amount, parsed.Extent = values[0], values[1]
+	default:
+		if len(values) > 0 {
+			parsed.Amount = values[0]
+		}
+	}
+	*i = parsed
+	return nil
+}
+
+// Write a color as #rrggbbaa, or none.
+func formatColor(c color.Color) string {
+	if c == nil {
+		return "none"
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", n.R, n.G, n.B, n.A)
+}
+
+// Parse a color written as #rrggbbaa, #rrggbb, or none.
+func parseColor(s string) (color.Color, error) {
+	if s == "none" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(s, "#") || (len(s) != 7 && len(s) != 9) {
+		return nil, fmt.Errorf("%w: bad color %q", ErrBadInstruction, s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad color %q", ErrBadInstruction, s)
+	}
+	if len(s) == 7 {
+		v = v<<8 | 0xff
+	}
+	return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// Write instructions as text, one per line.
+type InstructionWriter struct {
+	w *bufio.Writer
+}
+
+// Create a new InstructionWriter, writing on w.
+func NewInstructionWriter(w io.Writer) *InstructionWriter {
+	return &InstructionWriter{bufio.NewWriter(w)}
+}
+
+// Write an instruction.
+func (iw *InstructionWriter) Write(i Instruction) error {
+	b, err := i.MarshalText()
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	_, err = iw.w.Write(b)
+	return err
+}
+
+// Write all the instructions received on the channel, until it is closed.
+//
+// Returns the number of instructions written.
+// The writer is flushed at the end.
+func (iw *InstructionWriter) WriteAll(instructions <-chan Instruction) (int, error) {
+	n := 0
+	for i := range instructions {
+		if err := iw.Write(i); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, iw.Flush()
+}
+
+// Write the buffered instructions to the underlying writer.
+func (iw *InstructionWriter) Flush() error {
+	return iw.w.Flush()
+}
+
+// Read instructions written as text, one per line.
+//
+// Empty lines and lines starting with # are skipped.
+type InstructionReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+// Create a new InstructionReader, reading from r.
+func NewInstructionReader(r io.Reader) *InstructionReader {
+	return &InstructionReader{s: bufio.NewScanner(r)}
+}
+
+// Read the next instruction.
+//
+// Returns io.EOF at the end of the input,
+// the other errors report the line number.
+func (ir *InstructionReader) Read() (Instruction, error) {
+	for ir.s.Scan() {
+		ir.line++
+		text := strings.TrimSpace(ir.s.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var i Instruction
+		if err := i.UnmarshalText([]byte(text)); err != nil {
+			return Instruction{}, fmt.Errorf("line %d: %w", ir.line, err)
+		}
+		return i, nil
+	}
+	if err := ir.s.Err(); err != nil {
+		return Instruction{}, err
+	}
+	return Instruction{}, io.EOF
+}
+
+// Read all the instructions and send them on the channel,
+// that is closed at the end, like the fractal generators do.
+func (ir *InstructionReader) ReadAll(instructions chan<- Instruction) error {
+	defer close(instructions)
+	for {
+		i, err := ir.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		instructions <- i
+	}
+}
@@ -0,0 +1,101 @@
+package turtle_test
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Get a stream that uses every command, with values hard to round trip.
+func mixedInstructions() []turtle.Instruction {
+	return []turtle.Instruction{
+		{Cmd: turtle.CmdForward, Amount: 10},
+		{Cmd: turtle.CmdForward, Amount: 10},
+		{Cmd: turtle.CmdForward, Amount: 10},
+		{Cmd: turtle.CmdLeft, Amount: 90},
+		{Cmd: turtle.CmdForward, Amount: 0.1},
+		{Cmd: turtle.CmdRight, Amount: 360.0 / 7},
+		{Cmd: turtle.CmdForward, Amount: 10},
+		{Cmd: turtle.CmdBackward, Amount: math.Copysign(0, -1)},
+		{Cmd: turtle.CmdBackward, Amount: 1e300},
+		{Cmd: turtle.CmdBackward, Amount: 5e-324},
+		{Cmd: turtle.CmdBackward, Amount: -math.Pi},
+		{Cmd: turtle.CmdPitchUp, Amount: 1.5},
+		{Cmd: turtle.CmdPitchDown, Amount: 2},
+		{Cmd: turtle.CmdRollLeft, Amount: 2.5},
+		{Cmd: turtle.CmdRollRight, Amount: 3},
+		{Cmd: turtle.CmdPenUp},
+		{Cmd: turtle.CmdPenDown},
+		{Cmd: turtle.CmdSetColor, Color: turtle.DarkOrange},
+		{Cmd: turtle.CmdSetColor, Color: color.NRGBA{1, 2, 3, 4}},
+		{Cmd: turtle.CmdSetColor},
+		{Cmd: turtle.CmdSetSize, Amount: 3},
+		{Cmd: turtle.CmdSetPos, X: 100.25, Y: -1.0 / 3},
+		{Cmd: turtle.CmdSetPos, X: 100.25, Y: -1.0 / 3},
+		{Cmd: turtle.CmdSetHeading, Amount: 45},
+		{Cmd: turtle.CmdPush},
+		{Cmd: turtle.CmdPop},
+		{Cmd: turtle.CmdCircle, Amount: 50, Extent: 180},
+		{Cmd: turtle.CmdCircle, Amount: -0.7, Extent: 0},
+		{Cmd: turtle.CmdForward, Amount: 10},
+	}
+}
+
+// Check if two instructions are the same, comparing the bits of the values,
+// and the colors as non premultiplied RGBA.
+func sameInstruction(a, b turtle.Instruction) bool {
+	same := func(x, y float64) bool { return math.Float64bits(x) == math.Float64bits(y) }
+	if a.Cmd != b.Cmd || !same(a.Amount, b.Amount) || !same(a.Extent, b.Extent) ||
+		!same(a.X, b.X) || !same(a.Y, b.Y) {
+		return false
+	}
+	if a.Color == nil || b.Color == nil {
+		return a.Color == nil && b.Color == nil
+	}
+	return color.NRGBAModel.Convert(a.Color) == color.NRGBAModel.Convert(b.Color)
+}
+
+// Check that the instructions got are the ones wanted.
+func checkInstructions(t *testing.T, got, want []turtle.Instruction) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d instructions, want %d", len(got), len(want))
+	}
+	for k := range want {
+		if !sameInstruction(got[k], want[k]) {
+			t.Errorf("instruction %d: got %v, want %v", k, got[k], want[k])
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	want := mixedInstructions()
+	var buf bytes.Buffer
+	iw := turtle.NewInstructionWriter(&buf)
+	for _, i := range want {
+		if err := iw.Write(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := iw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []turtle.Instruction
+	ir := turtle.NewInstructionReader(&buf)
+	for {
+		i, err := ir.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, i)
+	}
+	checkInstructions(t, got, want)
+}
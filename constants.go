@@ -1,6 +1,9 @@
 package turtle
 
-import "image/color"
+import (
+	"image/color"
+	"math"
+)
 
 // Standard directions.
 const (
@@ -10,6 +13,13 @@ const (
 	South = 270.0
 )
 
+// Size of a full turn in standard angle units, to use with SetFullCircle.
+const (
+	Degrees  = 360.0
+	Radians  = 2 * math.Pi
+	Gradians = 400.0
+)
+
 // Standard colors.
 var (
 	Black     = color.RGBA{0, 0, 0, 255}
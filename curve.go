@@ -0,0 +1,143 @@
+package turtle
+
+import "math"
+
+// Max distance in pixels between a curve and the lines that approximate it.
+const curveTolerance = 0.25
+
+// Max number of times a curve is split in half while flattening.
+const curveMaxDepth = 16
+
+// Draw a quadratic Bezier curve from the current position to end,
+// with control point c, in World coordinates.
+//
+// The turtle ends in end, oriented along the tangent of the curve.
+func (td *TurtleDraw) QuadTo(c, end Point) {
+	// a quadratic is a cubic with the control points at 2/3 of the way
+	start := Point{td.X, td.Y}
+	c1 := Point{start.X + 2.0/3*(c.X-start.X), start.Y + 2.0/3*(c.Y-start.Y)}
+	c2 := Point{end.X + 2.0/3*(c.X-end.X), end.Y + 2.0/3*(c.Y-end.Y)}
+	td.CubicTo(c1, c2, end)
+}
+
+// Draw a cubic Bezier curve from the current position to end,
+// with control points c1 and c2, in World coordinates.
+//
+// The turtle ends in end, oriented along the tangent of the curve.
+func (td *TurtleDraw) CubicTo(c1, c2, end Point) {
+	td.do(func() {
+		start := Point{td.X, td.Y}
+		td.followCubic(start, c1, c2, end)
+		td.faceTangent(start, c1, c2, end)
+	})
+}
+
+// Draw a quadratic Bezier curve, with the points in the frame of the turtle:
+// the X axis points forward, the Y axis to the left.
+func (td *TurtleDraw) QuadRel(c, end Point) {
+	td.QuadTo(td.toWorld(c), td.toWorld(end))
+}
+
+// Draw a cubic Bezier curve, with the points in the frame of the turtle:
+// the X axis points forward, the Y axis to the left.
+func (td *TurtleDraw) CubicRel(c1, c2, end Point) {
+	td.CubicTo(td.toWorld(c1), td.toWorld(c2), td.toWorld(end))
+}
+
+// Draw a Catmull-Rom spline from the current position through the points,
+// in World coordinates.
+//
+// The turtle ends in the last point, oriented along the tangent of the curve.
+func (td *TurtleDraw) SplineTo(points ...Point) {
+	if len(points) == 0 {
+		return
+	}
+	td.do(func() {
+		// the ends are repeated, so that the curve passes through them
+		pts := make([]Point, 0, len(points)+3)
+		start := Point{td.X, td.Y}
+		pts = append(pts, start, start)
+		pts = append(pts, points...)
+		pts = append(pts, points[len(points)-1])
+
+		// each span is a cubic Bezier with the same tangents
+		for i := 1; i+2 < len(pts); i++ {
+			p0, p1, p2, p3 := pts[i-1], pts[i], pts[i+1], pts[i+2]
+			c1 := Point{p1.X + (p2.X-p0.X)/6, p1.Y + (p2.Y-p0.Y)/6}
+			c2 := Point{p2.X - (p3.X-p1.X)/6, p2.Y - (p3.Y-p1.Y)/6}
+			td.followCubic(p1, c1, c2, p2)
+			td.faceTangent(p1, c1, c2, p2)
+		}
+	})
+}
+
+// Draw a Catmull-Rom spline, with the points in the frame of the turtle:
+// the X axis points forward, the Y axis to the left.
+func (td *TurtleDraw) SplineRel(points ...Point) {
+	abs := make([]Point, len(points))
+	for i, p := range points {
+		abs[i] = td.toWorld(p)
+	}
+	td.SplineTo(abs...)
+}
+
+// Convert a point from the frame of the turtle to World coordinates.
+func (td *TurtleDraw) toWorld(p Point) Point {
+	p = p.Rotate(td.Deg)
+	return Point{td.X + p.X, td.Y + p.Y}
+}
+
+// Move along the cubic Bezier curve, drawing it as short lines.
+func (td *TurtleDraw) followCubic(p0, p1, p2, p3 Point) {
+	td.stats.Moves++
+	for _, p := range flattenCubic(p0, p1, p2, p3, nil, 0) {
+		x0, y0 := td.X, td.Y
+		td.X, td.Y = p.X, p.Y
+		td.travel(x0, y0)
+	}
+}
+
+// Orient the turtle along the tangent at the end of the cubic Bezier curve.
+func (td *TurtleDraw) faceTangent(p0, p1, p2, p3 Point) {
+	// the tangent is from the last control point that is not on the end
+	for _, c := range []Point{p2, p1, p0} {
+		if c != p3 {
+			td.Deg = normDeg(Rad2deg(math.Atan2(p3.Y-c.Y, p3.X-c.X)))
+			return
+		}
+	}
+}
+
+// Split the cubic Bezier curve until each piece is flat,
+// appending to pts the end of each piece.
+//
+// https://en.wikipedia.org/wiki/De_Casteljau%27s_algorithm
+func flattenCubic(p0, p1, p2, p3 Point, pts []Point, depth int) []Point {
+	if depth >= curveMaxDepth || cubicFlat(p0, p1, p2, p3) {
+		return append(pts, p3)
+	}
+	mid := func(a, b Point) Point { return Point{(a.X + b.X) / 2, (a.Y + b.Y) / 2} }
+	p01, p12, p23 := mid(p0, p1), mid(p1, p2), mid(p2, p3)
+	p012, p123 := mid(p01, p12), mid(p12, p23)
+	p0123 := mid(p012, p123)
+	pts = flattenCubic(p0, p01, p012, p0123, pts, depth+1)
+	return flattenCubic(p0123, p123, p23, p3, pts, depth+1)
+}
+
+// Check if the control points are close enough to the chord from p0 to p3.
+func cubicFlat(p0, p1, p2, p3 Point) bool {
+	return distToSegment(p1, p0, p3) <= curveTolerance &&
+		distToSegment(p2, p0, p3) <= curveTolerance
+}
+
+// Get the distance of p from the segment from a to b.
+func distToSegment(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	l2 := dx*dx + dy*dy
+	if l2 == 0 {
+		return math.Hypot(p.X-a.X, p.Y-a.Y)
+	}
+	t := ((p.X-a.X)*dx + (p.Y-a.Y)*dy) / l2
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.X-a.X-t*dx, p.Y-a.Y-t*dy)
+}
@@ -0,0 +1,86 @@
+package turtle_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Check if a pixel within one of (x, y) was drawn.
+func drawnNear(w *turtle.World, x, y float64) bool {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if drawn(w, int(x)+dx, int(y)+dy) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestCubicTo(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 10, 10)
+	td.PenDown()
+	p0, p1, p2, p3 := turtle.Point{X: 10, Y: 10}, turtle.Point{X: 10, Y: 90}, turtle.Point{X: 90, Y: 90}, turtle.Point{X: 90, Y: 10}
+	td.CubicTo(p1, p2, p3)
+
+	if td.X != 90 || td.Y != 10 || td.Deg != 270 {
+		t.Errorf("got (%v, %v) heading %v, want (90, 10) heading 270", td.X, td.Y, td.Deg)
+	}
+	for k := 0; k <= 20; k++ {
+		u := float64(k) / 20
+		a, b, c, d := (1-u)*(1-u)*(1-u), 3*u*(1-u)*(1-u), 3*u*u*(1-u), u*u*u
+		x := a*p0.X + b*p1.X + c*p2.X + d*p3.X
+		y := a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y
+		if !drawnNear(w, x, y) {
+			t.Errorf("the curve at t=%v, (%v, %v), was not drawn", u, x, y)
+		}
+	}
+	if s := td.Stats(); s.Moves != 1 || s.Segments < 8 {
+		t.Errorf("got %d moves and %d segments, want 1 move and a smooth curve", s.Moves, s.Segments)
+	}
+}
+
+func TestQuadRel(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 50, 10)
+	td.Left(90)
+	// a symmetric arc to the left, ending straight ahead
+	td.QuadRel(turtle.Point{X: 20, Y: 20}, turtle.Point{X: 40, Y: 0})
+
+	if math.Abs(td.X-50) > 1e-9 || math.Abs(td.Y-50) > 1e-9 {
+		t.Errorf("got (%v, %v), want (50, 50)", td.X, td.Y)
+	}
+	// the tangent at the end goes from the control point to the end
+	if math.Abs(td.Deg-45) > 1e-9 {
+		t.Errorf("got heading %v, want 45", td.Deg)
+	}
+}
+
+func TestSplineTo(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 10, 50)
+	td.PenDown()
+	pts := []turtle.Point{{X: 30, Y: 80}, {X: 50, Y: 20}, {X: 70, Y: 80}, {X: 90, Y: 50}}
+	td.SplineTo(pts...)
+
+	if td.X != 90 || td.Y != 50 {
+		t.Errorf("got (%v, %v), want (90, 50)", td.X, td.Y)
+	}
+	for _, p := range pts {
+		if !drawnNear(w, p.X, p.Y) {
+			t.Errorf("the spline does not pass through (%v, %v)", p.X, p.Y)
+		}
+	}
+
+	// no points, no move
+	td.SplineTo()
+	if td.X != 90 || td.Y != 50 {
+		t.Errorf("got (%v, %v) after an empty spline, want (90, 50)", td.X, td.Y)
+	}
+}
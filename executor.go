@@ -0,0 +1,54 @@
+package turtle
+
+import "context"
+
+// Something that executes instructions, like a Turtle or a TurtleDraw.
+type Executor interface {
+	DoInstruction(i Instruction) error
+}
+
+var (
+	_ Executor = &Turtle{}
+	_ Executor = &TurtleDraw{}
+	_ Executor = &Turtle3D{}
+	_ Executor = &TurtleDraw3D{}
+)
+
+// Execute the instructions received on the channel, until it is closed.
+//
+// Stops at the first error returned by the Executor, or when ctx is done,
+// returning its error.
+// When stopping early the channel is drained in the background,
+// so that the generator sending on it can finish.
+//
+// Returns the number of instructions executed without error.
+func Run(ctx context.Context, exec Executor, instructions <-chan Instruction) (int, error) {
+	n := 0
+	for {
+		// a ready channel would win over the cancellation half of the times
+		if err := ctx.Err(); err != nil {
+			go drain(instructions)
+			return n, err
+		}
+		select {
+		case <-ctx.Done():
+			go drain(instructions)
+			return n, ctx.Err()
+		case i, ok := <-instructions:
+			if !ok {
+				return n, nil
+			}
+			if err := exec.DoInstruction(i); err != nil {
+				go drain(instructions)
+				return n, err
+			}
+			n++
+		}
+	}
+}
+
+// Receive and discard all the instructions, until the channel is closed.
+func drain(instructions <-chan Instruction) {
+	for range instructions {
+	}
+}
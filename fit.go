@@ -0,0 +1,94 @@
+package turtle
+
+import "math"
+
+// How to handle the aspect ratio when fitting lines in an image.
+type FitMode byte
+
+const (
+	FitContain FitMode = iota // Keep the aspect ratio, the drawing fits inside.
+	FitCover                  // Keep the aspect ratio, the drawing covers the image.
+	FitStretch                // Scale each axis independently.
+)
+
+// Get the bounding box of the lines.
+//
+// The size of the pen is not considered.
+func LinesBounds(lines []Line) (minX, minY, maxX, maxY float64) {
+	if len(lines) == 0 {
+		return 0, 0, 0, 0
+	}
+	minX, minY = math.Inf(1), math.Inf(1)
+	maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, l := range lines {
+		minX = math.Min(minX, math.Min(l.X0, l.X1))
+		minY = math.Min(minY, math.Min(l.Y0, l.Y1))
+		maxX = math.Max(maxX, math.Max(l.X0, l.X1))
+		maxY = math.Max(maxY, math.Max(l.Y0, l.Y1))
+	}
+	return minX, minY, maxX, maxY
+}
+
+// Scale and translate the lines to fill an image of size (width, height),
+// leaving pad pixels free on each side.
+//
+// The drawing is centered in the image.
+// The pad should be large enough to contain half of the pen size.
+func FitLines(lines []Line, width, height int, pad float64, mode FitMode) []Line {
+	minX, minY, maxX, maxY := LinesBounds(lines)
+	dx := maxX - minX
+	dy := maxY - minY
+
+	// the last usable pixel is at width-1
+	availX := float64(width-1) - 2*pad
+	availY := float64(height-1) - 2*pad
+
+	// scale factors for each axis, a degenerate axis can take any value
+	sx := math.Inf(1)
+	if dx > 0 {
+		sx = availX / dx
+	}
+	sy := math.Inf(1)
+	if dy > 0 {
+		sy = availY / dy
+	}
+
+	switch mode {
+	case FitContain:
+		sx = math.Min(sx, sy)
+		sy = sx
+	case FitCover:
+		// ignore the degenerate axis
+		switch {
+		case dx == 0:
+			sx = sy
+		case dy == 0:
+			sy = sx
+		default:
+			sx = math.Max(sx, sy)
+			sy = sx
+		}
+	}
+
+	// both axis are degenerate: a single point
+	if math.IsInf(sx, 1) {
+		sx = 1
+	}
+	if math.IsInf(sy, 1) {
+		sy = 1
+	}
+
+	// center the drawing
+	offX := float64(width-1)/2 - (minX+maxX)/2*sx
+	offY := float64(height-1)/2 - (minY+maxY)/2*sy
+
+	fitted := make([]Line, len(lines))
+	for i, l := range lines {
+		l.X0 = l.X0*sx + offX
+		l.Y0 = l.Y0*sy + offY
+		l.X1 = l.X1*sx + offX
+		l.Y1 = l.Y1*sy + offY
+		fitted[i] = l
+	}
+	return fitted
+}
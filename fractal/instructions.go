@@ -13,6 +13,9 @@ import (
 // angle: how much to rotate.
 // forward: how much to move forward.
 //
+// The 3D rotations use the symbols & ^ \ / to pitch down/up and roll left/right.
+// The symbols [ and ] push and pop the state of the turtle, to draw branches.
+//
 // Two mildly different rewrite rules can be used:
 // using ABCD, the forward movement must be explicit, using an F.
 // using XYWZ, the forward movement is done when the base of the recursion is reached.
@@ -42,6 +45,22 @@ func Instructions(
 		case '-':
 			instructions <- turtle.Instruction{Cmd: turtle.CmdRight, Amount: angle}
 
+		// rotations in 3D
+		case '&':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdPitchDown, Amount: angle}
+		case '^':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdPitchUp, Amount: angle}
+		case '\\':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdRollLeft, Amount: angle}
+		case '/':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdRollRight, Amount: angle}
+
+		// branches
+		case '[':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdPush}
+		case ']':
+			instructions <- turtle.Instruction{Cmd: turtle.CmdPop}
+
 		case 'F':
 			instructions <- turtle.Instruction{Cmd: turtle.CmdForward, Amount: forward}
 
@@ -104,3 +123,30 @@ func GenerateSierpinskiTriangle(level int, instructions chan<- turtle.Instructio
 	rules := map[byte]string{'X': "X-Y+X+Y-X", 'Y': "YY"}
 	Instructions(level, instructions, "X-Y-Y", rules, 120, forward)
 }
+
+// Generate instructions to draw a fractal plant, growing along the heading of the turtle.
+//
+// The rules X -> F+[[X]-X]-F[-FX]+X and F -> FF of the original are written
+// with A for X and Y for F.
+//
+// https://en.wikipedia.org/wiki/L-system#Example_7:_fractal_plant
+func GeneratePlant(level int, instructions chan<- turtle.Instruction, forward float64) {
+	rules := map[byte]string{'A': "Y+[[A]-A]-Y[-YA]+A", 'Y': "YY"}
+	Instructions(level, instructions, "A", rules, 25, forward)
+}
+
+// Generate instructions to draw a 3D Hilbert curve, for a Turtle3D.
+//
+// The turn around symbol | of the original rules is written as ++.
+//
+// The Algorithmic Beauty of Plants, figure 1.14
+// http://algorithmicbotany.org/papers/#abop
+func GenerateHilbert3D(level int, instructions chan<- turtle.Instruction, forward float64) {
+	rules := map[byte]string{
+		'A': "B-F+CFC+F-D&F^D-F+&&CFC+F+B//",
+		'B': "A&F^CFB^F^D^^-F-D^++F^B++FC^F^A//",
+		'C': "++D^++F^B-F+C^F^A&&FA&F^C+F+B^F^D//",
+		'D': "++CFB-F+B++FA&F^A&&FB-F+B++FC//",
+	}
+	Instructions(level, instructions, "A", rules, 90, forward)
+}
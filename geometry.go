@@ -0,0 +1,57 @@
+package turtle
+
+import "math"
+
+// Get the heading that points from the Turtle towards (x, y),
+// in the current angle units.
+func (t *Turtle) Towards(x, y float64) float64 {
+	rad := math.Atan2(y-t.Y, x-t.X)
+	return t.fromDeg(normDeg(Rad2deg(rad)))
+}
+
+// Get the distance from the Turtle to (x, y).
+func (t *Turtle) DistanceTo(x, y float64) float64 {
+	return math.Hypot(x-t.X, y-t.Y)
+}
+
+// Orient the Turtle towards (x, y).
+//
+// If the Turtle is already in (x, y), the heading does not change.
+func (t *Turtle) FaceTowards(x, y float64) {
+	if x == t.X && y == t.Y {
+		return
+	}
+	t.SetHeading(t.Towards(x, y))
+}
+
+// Move the Turtle to (x, y), keeping the heading.
+func (t *Turtle) Goto(x, y float64) {
+	t.SetPos(x, y)
+}
+
+// Move the Turtle by (dx, dy), keeping the heading.
+func (t *Turtle) Offset(dx, dy float64) {
+	t.SetPos(t.X+dx, t.Y+dy)
+}
+
+// Move the Turtle sideways to the left by dist, keeping the heading.
+//
+// In precise mode the move is along the exact unit vector of the heading,
+// like Forward.
+func (t *Turtle) StrafeLeft(dist float64) {
+	if !t.precise {
+		rad := Deg2rad(t.Deg + 90)
+		t.SetPos(t.X+dist*math.Cos(rad), t.Y+dist*math.Sin(rad))
+		return
+	}
+	x0, y0 := t.X, t.Y
+	// the left of the heading (ux, uy) is (-uy, ux)
+	ux, uy := unitVector(t.Deg)
+	t.stepPrecise(-dist*uy, dist*ux)
+	t.recordMove(x0, y0)
+}
+
+// Move the Turtle sideways to the right by dist, keeping the heading.
+func (t *Turtle) StrafeRight(dist float64) {
+	t.StrafeLeft(-dist)
+}
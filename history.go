@@ -0,0 +1,275 @@
+package turtle
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+)
+
+// The state of a TurtleDraw, saved around each operation.
+type drawState struct {
+	Turtle Turtle
+	Pen    Pen
+	Layer  string
+	Shape  Shape
+	Stack  []drawPose
+
+	Boundary BoundaryMode
+}
+
+// A single TurtleDraw operation.
+type operation struct {
+	td     *TurtleDraw
+	before drawState
+	after  drawState
+	drawn  bool   // Some line was drawn by the operation.
+	stamp  *stamp // Stamp done by the operation, if any.
+	undone bool
+}
+
+// A line drawn on the World, with the operation that drew it.
+type drawnLine struct {
+	line Line
+	op   *operation // nil for the lines drawn without a TurtleDraw.
+}
+
+// The operation history of a World.
+type history struct {
+	mu     sync.Mutex
+	ops    []*operation
+	lines  []drawnLine            // The lines drawn, in order.
+	base   *image.RGBA            // The image when the history started.
+	layers map[string]*image.RGBA // The layers when the history started.
+	chunks *chunkImage            // The image of an unbounded World.
+}
+
+// Start keeping the history of the operations done by the turtles,
+// so that they can be undone.
+//
+// The current image and layers are the starting point of the history.
+// Every line drawn is kept in memory, so avoid it for huge drawings.
+// The lines drawn without a TurtleDraw, like the ones of DrawLines or of a TurtleDraw3D,
+// are kept too, and survive Undo.
+func (w *World) EnableHistory() {
+	if w.chunks != nil {
+		w.history = &history{chunks: w.chunks.clone()}
+		return
+	}
+	h := &history{
+		base:   cloneRGBA(w.Image),
+		layers: make(map[string]*image.RGBA),
+	}
+	for _, l := range w.layers {
+		h.layers[l.Name] = cloneRGBA(l.Image)
+	}
+	w.history = h
+}
+
+// Stop keeping the history, and forget it.
+func (w *World) DisableHistory() {
+	w.history = nil
+}
+
+// Lock the history, if enabled, returning the function to unlock it.
+func (w *World) lockHistory() func() {
+	h := w.history
+	if h == nil {
+		return func() {}
+	}
+	h.mu.Lock()
+	return h.mu.Unlock
+}
+
+// Add an operation, forgetting the undone operations of the same turtle.
+func (h *history) push(op *operation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	forgotten := func(o *operation) bool {
+		return o != nil && o.undone && o.td == op.td
+	}
+	kept := h.ops[:0]
+	for _, o := range h.ops {
+		if !forgotten(o) {
+			kept = append(kept, o)
+		} else if o.stamp != nil {
+			// the stamp can not be redone anymore
+			o.stamp.cleared = true
+		}
+	}
+	h.ops = append(kept, op)
+
+	lines := h.lines[:0]
+	for _, l := range h.lines {
+		if !forgotten(l.op) {
+			lines = append(lines, l)
+		}
+	}
+	h.lines = lines
+}
+
+// Keep a line drawn by the operation op, nil if drawn without a TurtleDraw.
+func (h *history) record(l Line, op *operation) {
+	// the stamps are drawn again from their own lines
+	if op != nil && op.stamp != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lines = append(h.lines, drawnLine{l, op})
+	if op != nil {
+		op.drawn = true
+	}
+}
+
+// Draw again the image from the start of the history,
+// skipping the undone operations, from the listen goroutine.
+func (w *World) rerender() {
+	h := w.history
+	if h.chunks != nil {
+		w.chunks = h.chunks.clone()
+	} else {
+		draw.Draw(w.Image, w.Image.Bounds(), h.base, h.base.Bounds().Min, draw.Src)
+	}
+	for _, l := range w.layers {
+		if m, ok := h.layers[l.Name]; ok {
+			draw.Draw(l.Image, l.Image.Bounds(), m, m.Bounds().Min, draw.Src)
+		} else {
+			draw.Draw(l.Image, l.Image.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		}
+	}
+	h.mu.Lock()
+	var lines []Line
+	for _, l := range h.lines {
+		if l.op == nil || !l.op.undone {
+			lines = append(lines, l.line)
+		}
+	}
+	h.mu.Unlock()
+	w.drawLinesParallel(lines, 0)
+
+	// the stamps are not in the operations
+	w.redrawStamps()
+}
+
+// Undo the last n operations of the TurtleDraw,
+// restoring its state and the image.
+//
+// Returns the number of operations actually undone.
+// The World history must be enabled.
+func (td *TurtleDraw) Undo(n int) int {
+	h := td.W.history
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	done := 0
+	drew := false
+	var first *operation
+	for i := len(h.ops) - 1; i >= 0 && done < n; i-- {
+		op := h.ops[i]
+		if op.td != td || op.undone {
+			continue
+		}
+		op.undone = true
+		if op.stamp != nil {
+			op.stamp.undone = true
+		}
+		drew = drew || op.drawn || op.stamp != nil
+		first = op
+		done++
+	}
+	h.mu.Unlock()
+
+	if first != nil {
+		td.restore(first.before)
+	}
+	// the image only changes if some line was removed
+	if drew {
+		td.W.run(td.W.rerender)
+	}
+	return done
+}
+
+// Redo the last n undone operations of the TurtleDraw,
+// restoring its state and the image.
+//
+// Returns the number of operations actually redone.
+// A new operation forgets the undone ones, that can not be redone anymore.
+func (td *TurtleDraw) Redo(n int) int {
+	h := td.W.history
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	done := 0
+	drew := false
+	var last *operation
+	for _, op := range h.ops {
+		if done == n {
+			break
+		}
+		if op.td != td || !op.undone {
+			continue
+		}
+		op.undone = false
+		if op.stamp != nil {
+			op.stamp.undone = false
+		}
+		drew = drew || op.drawn || op.stamp != nil
+		last = op
+		done++
+	}
+	h.mu.Unlock()
+
+	if last != nil {
+		td.restore(last.after)
+	}
+	if drew {
+		td.W.run(td.W.rerender)
+	}
+	return done
+}
+
+// Record the function f as a single operation in the World history.
+//
+// Nested calls are part of the outer operation.
+func (td *TurtleDraw) do(f func()) {
+	h := td.W.history
+	if h == nil || td.op != nil {
+		f()
+		return
+	}
+	op := &operation{td: td, before: td.state()}
+	td.op = op
+	f()
+	td.op = nil
+	op.after = td.state()
+	h.push(op)
+}
+
+// Get the current state.
+func (td *TurtleDraw) state() drawState {
+	// the stacks are modified in place, copy them
+	t := td.Turtle
+	t.stack = append([]pose(nil), t.stack...)
+	stack := append([]drawPose(nil), td.stack...)
+	return drawState{t, td.Pen, td.Layer, td.Shape, stack, td.Boundary}
+}
+
+// Set the current state.
+func (td *TurtleDraw) restore(s drawState) {
+	td.Turtle = s.Turtle
+	td.Pen = s.Pen
+	td.Layer = s.Layer
+	td.Shape = s.Shape
+	td.Boundary = s.Boundary
+	td.Turtle.stack = append([]pose(nil), s.Turtle.stack...)
+	td.stack = append([]drawPose(nil), s.Stack...)
+}
+
+// Copy an image.
+func cloneRGBA(m *image.RGBA) *image.RGBA {
+	c := image.NewRGBA(m.Bounds())
+	copy(c.Pix, m.Pix)
+	return c
+}
@@ -0,0 +1,129 @@
+package turtle_test
+
+import (
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+func TestUndoRedo(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetSize(1)
+	td.SetPos(10, 10)
+	w.EnableHistory()
+
+	td.PenDown()
+	td.Forward(50)
+	td.Left(90)
+	td.Forward(50)
+	if !drawn(w, 60, 40) {
+		t.Fatal("the second line was not drawn")
+	}
+
+	if n := td.Undo(2); n != 2 {
+		t.Fatalf("undid %d operations, want 2", n)
+	}
+	if td.X != 60 || td.Y != 10 || td.Deg != 0 {
+		t.Errorf("got %v after Undo, want the pose after the first line", td)
+	}
+	if drawn(w, 60, 40) || !drawn(w, 30, 10) {
+		t.Error("Undo did not remove only the second line")
+	}
+
+	if n := td.Redo(5); n != 2 {
+		t.Fatalf("redid %d operations, want 2", n)
+	}
+	if !drawn(w, 60, 40) || td.Deg != 90 {
+		t.Error("Redo did not restore the second line")
+	}
+
+	// a new operation forgets the undone ones
+	td.Undo(1)
+	td.Right(45)
+	if n := td.Redo(1); n != 0 {
+		t.Errorf("redid %d operations after a new one, want 0", n)
+	}
+}
+
+func TestUndoKeepsOtherLines(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	w.EnableHistory()
+	td := turtle.NewTurtleDraw(w)
+	other := turtle.NewTurtleDraw(w)
+	for _, tu := range []*turtle.TurtleDraw{td, other} {
+		tu.SetSize(1)
+		tu.PenDown()
+	}
+
+	td.Forward(20)
+	other.Left(90)
+	other.Forward(20)
+	w.DrawLines([]turtle.Line{{X0: 50, Y0: 50, X1: 60, Y1: 50, Style: turtle.PenStyle{Color: turtle.Red, Size: 1}}})
+
+	td.Undo(1)
+	if drawn(w, 10, 0) {
+		t.Error("the undone line is still there")
+	}
+	if !drawn(w, 0, 10) {
+		t.Error("the line of the other turtle was removed")
+	}
+	if !drawn(w, 55, 50) {
+		t.Error("the line of DrawLines was removed")
+	}
+}
+
+func TestUndoStamp(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	w.EnableHistory()
+	td := turtle.NewTurtleDraw(w)
+	td.SetPos(50, 50)
+	td.SetShape(turtle.ShapeCircle)
+	td.Stamp()
+
+	stamped := func() bool {
+		_, _, _, a := w.Layer(turtle.StampLayer).Image.At(50, 49).RGBA()
+		return a != 0
+	}
+	if !stamped() {
+		t.Fatal("the stamp was not drawn")
+	}
+	td.Undo(1)
+	if stamped() {
+		t.Error("the undone stamp is still there")
+	}
+	td.Redo(1)
+	if !stamped() {
+		t.Error("the redone stamp is missing")
+	}
+}
+
+func TestUndoConcurrent(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	w.EnableHistory()
+	a := turtle.NewTurtleDraw(w)
+	b := turtle.NewTurtleDraw(w)
+	a.PenDown()
+	b.PenDown()
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 200; i++ {
+			a.Forward(7)
+			a.Left(61)
+		}
+		done <- true
+	}()
+	for i := 0; i < 50; i++ {
+		b.Forward(30)
+		b.Undo(1)
+	}
+	<-done
+	if b.X != 0 || b.Y != 0 {
+		t.Errorf("got %v, want the turtle back in the origin", b)
+	}
+}
@@ -0,0 +1,185 @@
+package turtle
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Errors returned when managing layers.
+var (
+	ErrLayerExists  = errors.New("turtle: layer already exists")
+	ErrLayerMissing = errors.New("turtle: layer does not exist")
+	ErrLayerName    = errors.New("turtle: layer name must not be empty")
+)
+
+// A named transparent image, drawn on top of the World background.
+type Layer struct {
+	Name    string
+	Image   *image.RGBA
+	Hidden  bool    // Hidden layers are not composited.
+	Opacity float64 // Opacity in [0, 1] used when compositing.
+}
+
+// Create a new transparent Layer of the requested size.
+func newLayer(name string, width, height int) *Layer {
+	return &Layer{
+		Name:    name,
+		Image:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		Opacity: 1,
+	}
+}
+
+// Add a new Layer on top of the others.
+func (w *World) AddLayer(name string) (*Layer, error) {
+	if w.chunks != nil {
+		return nil, ErrUnbounded
+	}
+	if name == "" {
+		return nil, ErrLayerName
+	}
+	if w.Layer(name) != nil {
+		return nil, ErrLayerExists
+	}
+	l := newLayer(name, w.Width, w.Height)
+	w.layers = append(w.layers, l)
+	return l, nil
+}
+
+// Get the Layer with the requested name, nil if missing.
+func (w *World) Layer(name string) *Layer {
+	i := w.layerIndex(name)
+	if i < 0 {
+		return nil
+	}
+	return w.layers[i]
+}
+
+// Get the layers, from the bottom to the top.
+func (w *World) Layers() []*Layer {
+	layers := make([]*Layer, len(w.layers))
+	copy(layers, w.layers)
+	return layers
+}
+
+// Remove a Layer, and everything drawn on it.
+func (w *World) RemoveLayer(name string) error {
+	i := w.layerIndex(name)
+	if i < 0 {
+		return ErrLayerMissing
+	}
+	w.layers = append(w.layers[:i], w.layers[i+1:]...)
+	return nil
+}
+
+// Move a Layer to position index, 0 is just above the background.
+//
+// The index is clamped to the valid range.
+func (w *World) MoveLayer(name string, index int) error {
+	i := w.layerIndex(name)
+	if i < 0 {
+		return ErrLayerMissing
+	}
+	l := w.layers[i]
+	w.layers = append(w.layers[:i], w.layers[i+1:]...)
+	if index < 0 {
+		index = 0
+	}
+	if index > len(w.layers) {
+		index = len(w.layers)
+	}
+	w.layers = append(w.layers[:index], append([]*Layer{l}, w.layers[index:]...)...)
+	return nil
+}
+
+// Show or hide a Layer.
+func (w *World) SetLayerHidden(name string, hidden bool) error {
+	l := w.Layer(name)
+	if l == nil {
+		return ErrLayerMissing
+	}
+	l.Hidden = hidden
+	return nil
+}
+
+// Change the opacity of a Layer, clamped in [0, 1].
+func (w *World) SetLayerOpacity(name string, opacity float64) error {
+	l := w.Layer(name)
+	if l == nil {
+		return ErrLayerMissing
+	}
+	if opacity < 0 {
+		opacity = 0
+	}
+	if opacity > 1 {
+		opacity = 1
+	}
+	l.Opacity = opacity
+	return nil
+}
+
+// Composite the background and the visible layers in a new image.
+//
+// If there are no layers, the background image is returned as is.
+// An unbounded World is cropped to the drawn extent plus Margin.
+func (w *World) Composite() *image.RGBA {
+	if w.chunks != nil {
+		return w.cropUnbounded()
+	}
+	if len(w.layers) == 0 {
+		return w.Image
+	}
+	b := w.Image.Bounds()
+	m := image.NewRGBA(b)
+	draw.Draw(m, b, w.Image, b.Min, draw.Src)
+	for _, l := range w.layers {
+		if l.Hidden || l.Opacity <= 0 {
+			continue
+		}
+		mask := &image.Uniform{color.Alpha{uint8(l.Opacity*255 + 0.5)}}
+		draw.DrawMask(m, b, l.Image, b.Min, mask, b.Min, draw.Over)
+	}
+	return m
+}
+
+// Save a single Layer, with a transparent background.
+func (w *World) SaveLayer(name, filePath string) error {
+	l := w.Layer(name)
+	if l == nil {
+		return ErrLayerMissing
+	}
+	return savePNG(filePath, l.Image)
+}
+
+// Find the position of a Layer, -1 if missing.
+func (w *World) layerIndex(name string) int {
+	for i, l := range w.layers {
+		if l.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Get the image to draw on for the layer name, nil if missing.
+func (w *World) layerImage(name string) *image.RGBA {
+	if name == "" {
+		return w.Image
+	}
+	l := w.Layer(name)
+	if l == nil {
+		return nil
+	}
+	return l.Image
+}
+
+// Get the background restored by the eraser on the layer name.
+//
+// The layers are transparent, so nil is returned.
+func (w *World) layerBackground(name string) image.Image {
+	if name == "" {
+		return w.background
+	}
+	return nil
+}
@@ -1,8 +1,12 @@
 package turtle
 
-// A simple Line with a Pen to send around channels.
+// A simple Line with a PenStyle to send around channels.
+//
+// The style is a copy of the Pen at the time the line was created,
+// so changing the Pen later does not affect the line.
 type Line struct {
 	X0, Y0 float64
 	X1, Y1 float64
-	p      *Pen
+	Style  PenStyle // How to draw the line.
+	Layer  string   // Name of the layer to draw on, empty for the background.
 }
@@ -0,0 +1,109 @@
+package logo
+
+// A parsed program, ready to run.
+type Program struct {
+	Procs map[string]*Proc // Procedures defined with TO, by upper case name.
+	Body  []Stmt           // Top level statements.
+}
+
+// A procedure defined with TO ... END.
+type Proc struct {
+	Pos    Pos
+	Name   string
+	Params []string
+	Body   []Stmt
+}
+
+// A statement of the program.
+type Stmt interface {
+	stmtPos() Pos
+}
+
+// An expression, evaluating to a number.
+type Expr interface {
+	exprPos() Pos
+}
+
+// A turtle primitive, like FD 10.
+type Command struct {
+	Pos  Pos
+	Name string
+	Args []Expr
+}
+
+// A call of a procedure.
+type Call struct {
+	Pos  Pos
+	Name string
+	Args []Expr
+}
+
+// REPEAT count [ body ].
+type Repeat struct {
+	Pos   Pos
+	Count Expr
+	Body  []Stmt
+}
+
+// IF cond [ then ], or IFELSE cond [ then ] [ else ].
+type If struct {
+	Pos  Pos
+	Cond Expr
+	Then []Stmt
+	Else []Stmt
+}
+
+// MAKE "name value.
+type Make struct {
+	Pos   Pos
+	Name  string
+	Value Expr
+}
+
+// STOP, leave the current procedure.
+type Stop struct {
+	Pos Pos
+}
+
+// A number.
+type Number struct {
+	Pos   Pos
+	Value float64
+}
+
+// The value of a variable, :name.
+type Var struct {
+	Pos  Pos
+	Name string
+}
+
+// The iteration of the innermost REPEAT, starting from 1.
+type RepCount struct {
+	Pos Pos
+}
+
+// A binary operation: + - * / = < > <= >= <>.
+type Binary struct {
+	Pos  Pos
+	Op   string
+	L, R Expr
+}
+
+// A negated expression.
+type Neg struct {
+	Pos Pos
+	X   Expr
+}
+
+func (s *Command) stmtPos() Pos { return s.Pos }
+func (s *Call) stmtPos() Pos    { return s.Pos }
+func (s *Repeat) stmtPos() Pos  { return s.Pos }
+func (s *If) stmtPos() Pos      { return s.Pos }
+func (s *Make) stmtPos() Pos    { return s.Pos }
+func (s *Stop) stmtPos() Pos    { return s.Pos }
+
+func (e *Number) exprPos() Pos   { return e.Pos }
+func (e *Var) exprPos() Pos      { return e.Pos }
+func (e *RepCount) exprPos() Pos { return e.Pos }
+func (e *Binary) exprPos() Pos   { return e.Pos }
+func (e *Neg) exprPos() Pos      { return e.Pos }
@@ -0,0 +1,38 @@
+package logo
+
+import "fmt"
+
+// Position in the source of a program, starting from 1.
+type Pos struct {
+	Line, Col int
+}
+
+// Write the position as line:col.
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// An error in a program, while parsing or running it.
+type Error struct {
+	Pos Pos
+	Msg string
+	Err error // Error returned by the turtle, if any.
+}
+
+// Create a new Error at pos, formatting the message.
+func errorf(pos Pos, format string, a ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, a...)}
+}
+
+// Write the error with its position.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("logo: %s: %s: %v", e.Pos, e.Msg, e.Err)
+	}
+	return fmt.Sprintf("logo: %s: %s", e.Pos, e.Msg)
+}
+
+// Get the error returned by the turtle.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
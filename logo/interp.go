@@ -0,0 +1,247 @@
+package logo
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Max depth of the procedure calls, to stop runaway recursion.
+const maxDepth = 10000
+
+// Parse and run a Logo program on the turtle.
+func Run(src string, t turtle.Executor) error {
+	prog, err := Parse(src)
+	if err != nil {
+		return err
+	}
+	return prog.Run(t)
+}
+
+// Run the program on the turtle.
+//
+// Each run starts with no variables.
+// The parameters are local to the procedure, the other variables are global.
+func (prog *Program) Run(t turtle.Executor) error {
+	in := &interp{
+		prog:    prog,
+		target:  t,
+		globals: map[string]float64{},
+	}
+	err := in.execBlock(prog.Body)
+	if errors.Is(err, errStop) {
+		// STOP at the top level ends the program
+		return nil
+	}
+	return err
+}
+
+// Signal a STOP, unwinding to the procedure call.
+var errStop = errors.New("logo: stop")
+
+// State of a running program.
+type interp struct {
+	prog    *Program
+	target  turtle.Executor
+	globals map[string]float64
+	frames  []map[string]float64 // Local variables of the procedure calls.
+	repeats []int                // Iterations of the running REPEATs.
+}
+
+// Execute a list of statements.
+func (in *interp) execBlock(body []Stmt) error {
+	for _, s := range body {
+		if err := in.exec(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute a statement.
+func (in *interp) exec(s Stmt) error {
+	switch s := s.(type) {
+	case *Command:
+		args, err := in.evalArgs(s.Args)
+		if err != nil {
+			return err
+		}
+		err = in.target.DoInstruction(primitives[s.Name].instruction(args))
+		if err != nil {
+			return &Error{Pos: s.Pos, Msg: s.Name + " failed", Err: err}
+		}
+
+	case *Call:
+		return in.call(s)
+
+	case *Repeat:
+		count, err := in.eval(s.Count)
+		if err != nil {
+			return err
+		}
+		if count < 0 || count != math.Trunc(count) {
+			return errorf(s.Pos, "REPEAT needs a whole number of times, not %g", count)
+		}
+		in.repeats = append(in.repeats, 0)
+		defer func() { in.repeats = in.repeats[:len(in.repeats)-1] }()
+		for i := 1; i <= int(count); i++ {
+			in.repeats[len(in.repeats)-1] = i
+			if err := in.execBlock(s.Body); err != nil {
+				return err
+			}
+		}
+
+	case *If:
+		cond, err := in.eval(s.Cond)
+		if err != nil {
+			return err
+		}
+		if cond != 0 {
+			return in.execBlock(s.Then)
+		}
+		return in.execBlock(s.Else)
+
+	case *Make:
+		v, err := in.eval(s.Value)
+		if err != nil {
+			return err
+		}
+		in.set(s.Name, v)
+
+	case *Stop:
+		return errStop
+	}
+	return nil
+}
+
+// Call a procedure, binding its parameters.
+func (in *interp) call(c *Call) error {
+	if len(in.frames) >= maxDepth {
+		return errorf(c.Pos, "too many nested calls of %s", c.Name)
+	}
+	proc := in.prog.Procs[c.Name]
+	args, err := in.evalArgs(c.Args)
+	if err != nil {
+		return err
+	}
+	frame := make(map[string]float64, len(args))
+	for i, name := range proc.Params {
+		frame[name] = args[i]
+	}
+
+	in.frames = append(in.frames, frame)
+	err = in.execBlock(proc.Body)
+	in.frames = in.frames[:len(in.frames)-1]
+	if errors.Is(err, errStop) {
+		return nil
+	}
+	return err
+}
+
+// Evaluate a list of arguments.
+func (in *interp) evalArgs(exprs []Expr) ([]float64, error) {
+	args := make([]float64, len(exprs))
+	for i, e := range exprs {
+		v, err := in.eval(e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return args, nil
+}
+
+// Evaluate an expression.
+//
+// The comparisons are 1 if true, 0 if false.
+func (in *interp) eval(e Expr) (float64, error) {
+	switch e := e.(type) {
+	case *Number:
+		return e.Value, nil
+
+	case *Var:
+		v, ok := in.get(e.Name)
+		if !ok {
+			return 0, errorf(e.Pos, "%s has no value", e.Name)
+		}
+		return v, nil
+
+	case *RepCount:
+		if len(in.repeats) == 0 {
+			return 0, errorf(e.Pos, "REPCOUNT outside of REPEAT")
+		}
+		return float64(in.repeats[len(in.repeats)-1]), nil
+
+	case *Neg:
+		x, err := in.eval(e.X)
+		return -x, err
+
+	case *Binary:
+		l, err := in.eval(e.L)
+		if err != nil {
+			return 0, err
+		}
+		r, err := in.eval(e.R)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			if r == 0 {
+				return 0, errorf(e.Pos, "division by zero")
+			}
+			return l / r, nil
+		case "=":
+			return truth(l == r), nil
+		case "<>":
+			return truth(l != r), nil
+		case "<":
+			return truth(l < r), nil
+		case ">":
+			return truth(l > r), nil
+		case "<=":
+			return truth(l <= r), nil
+		case ">=":
+			return truth(l >= r), nil
+		}
+	}
+	return 0, errorf(e.exprPos(), "cannot evaluate the expression")
+}
+
+// Get the value of a variable, looking first in the current procedure.
+func (in *interp) get(name string) (float64, bool) {
+	if n := len(in.frames); n > 0 {
+		if v, ok := in.frames[n-1][name]; ok {
+			return v, true
+		}
+	}
+	v, ok := in.globals[name]
+	return v, ok
+}
+
+// Set a variable, a parameter of the current procedure if there is one,
+// else a global one.
+func (in *interp) set(name string, v float64) {
+	if n := len(in.frames); n > 0 {
+		if _, ok := in.frames[n-1][name]; ok {
+			in.frames[n-1][name] = v
+			return
+		}
+	}
+	in.globals[name] = v
+}
+
+// Convert a condition to a number.
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
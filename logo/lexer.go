@@ -0,0 +1,180 @@
+package logo
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kinds of token.
+type tokenKind byte
+
+const (
+	tokEOF    tokenKind = iota
+	tokNumber           // 3.14
+	tokWord             // FD, square
+	tokQuoted           // "size
+	tokVar              // :size
+	tokOp               // + - * / = < > <= >= <>
+	tokLBracket
+	tokRBracket
+	tokLParen
+	tokRParen
+)
+
+// A token of the source.
+type token struct {
+	kind  tokenKind
+	text  string  // Upper case for words, quoted names and variables.
+	num   float64 // Value of a number.
+	pos   Pos
+	unary bool // A minus sign written like -5, after a space.
+}
+
+// Split the source of a program in tokens.
+//
+// Words are case insensitive, a ; starts a comment up to the end of the line.
+func lex(src string) ([]token, error) {
+	l := lexer{src: []rune(src), line: 1, col: 1}
+	var toks []token
+	for {
+		space := l.skipSpace()
+		if l.i >= len(l.src) {
+			toks = append(toks, token{kind: tokEOF, pos: l.pos()})
+			return toks, nil
+		}
+		t, err := l.next(space)
+		if err != nil {
+			return nil, err
+		}
+		toks = append(toks, t)
+	}
+}
+
+// State of the lexer.
+type lexer struct {
+	src       []rune
+	i         int
+	line, col int
+}
+
+// Get the position of the next rune.
+func (l *lexer) pos() Pos {
+	return Pos{l.line, l.col}
+}
+
+// Look at the rune at offset from the current one, 0 past the end.
+func (l *lexer) peek(offset int) rune {
+	if l.i+offset >= len(l.src) {
+		return 0
+	}
+	return l.src[l.i+offset]
+}
+
+// Consume a rune.
+func (l *lexer) advance() rune {
+	r := l.src[l.i]
+	l.i++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+// Skip spaces and comments, reporting if something was skipped.
+func (l *lexer) skipSpace() bool {
+	skipped := false
+	for l.i < len(l.src) {
+		r := l.peek(0)
+		switch {
+		case unicode.IsSpace(r):
+			l.advance()
+		case r == ';':
+			for l.i < len(l.src) && l.peek(0) != '\n' {
+				l.advance()
+			}
+		default:
+			return skipped
+		}
+		skipped = true
+	}
+	return skipped
+}
+
+// Read the next token, space tells if it follows a space.
+func (l *lexer) next(space bool) (token, error) {
+	pos := l.pos()
+	r := l.peek(0)
+	switch {
+	case r == '[':
+		l.advance()
+		return token{kind: tokLBracket, text: "[", pos: pos}, nil
+	case r == ']':
+		l.advance()
+		return token{kind: tokRBracket, text: "]", pos: pos}, nil
+	case r == '(':
+		l.advance()
+		return token{kind: tokLParen, text: "(", pos: pos}, nil
+	case r == ')':
+		l.advance()
+		return token{kind: tokRParen, text: ")", pos: pos}, nil
+
+	case r == '<' || r == '>':
+		l.advance()
+		text := string(r)
+		if n := l.peek(0); n == '=' || (r == '<' && n == '>') {
+			text += string(l.advance())
+		}
+		return token{kind: tokOp, text: text, pos: pos}, nil
+	case r == '-':
+		l.advance()
+		// FD -5 is a negative number, FD 10 - 5 and FD 10-5 are subtractions
+		unary := space && !unicode.IsSpace(l.peek(0)) && l.peek(0) != 0
+		return token{kind: tokOp, text: "-", pos: pos, unary: unary}, nil
+	case strings.ContainsRune("+*/=", r):
+		l.advance()
+		return token{kind: tokOp, text: string(r), pos: pos}, nil
+
+	case unicode.IsDigit(r) || (r == '.' && unicode.IsDigit(l.peek(1))):
+		text := l.readWhile(func(r rune) bool { return unicode.IsDigit(r) || r == '.' })
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return token{}, errorf(pos, "bad number %s", text)
+		}
+		return token{kind: tokNumber, text: text, num: num, pos: pos}, nil
+
+	case r == '"' || r == ':':
+		l.advance()
+		name := l.readWhile(isWordRune)
+		if name == "" {
+			return token{}, errorf(pos, "missing name after %c", r)
+		}
+		kind := tokQuoted
+		if r == ':' {
+			kind = tokVar
+		}
+		return token{kind: kind, text: strings.ToUpper(name), pos: pos}, nil
+
+	case isWordRune(r):
+		word := l.readWhile(isWordRune)
+		return token{kind: tokWord, text: strings.ToUpper(word), pos: pos}, nil
+	}
+	return token{}, errorf(pos, "unexpected character %q", r)
+}
+
+// Read the runes while ok accepts them.
+func (l *lexer) readWhile(ok func(rune) bool) string {
+	start := l.i
+	for l.i < len(l.src) && ok(l.peek(0)) {
+		l.advance()
+	}
+	return string(l.src[start:l.i])
+}
+
+// Check if the rune can be part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '?'
+}
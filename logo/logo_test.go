@@ -0,0 +1,235 @@
+package logo_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/logo"
+)
+
+// Record the instructions executed, instead of moving a turtle.
+type recorder struct {
+	is []turtle.Instruction
+}
+
+// Record the instruction.
+func (r *recorder) DoInstruction(i turtle.Instruction) error {
+	r.is = append(r.is, i)
+	return nil
+}
+
+// Run the program, returning the instructions executed.
+func record(t *testing.T, src string) []turtle.Instruction {
+	t.Helper()
+	r := &recorder{}
+	if err := logo.Run(src, r); err != nil {
+		t.Fatal(err)
+	}
+	return r.is
+}
+
+// Shorthands for the instructions.
+func fd(d float64) turtle.Instruction { return turtle.Instruction{Cmd: turtle.CmdForward, Amount: d} }
+func lt(d float64) turtle.Instruction { return turtle.Instruction{Cmd: turtle.CmdLeft, Amount: d} }
+func rt(d float64) turtle.Instruction { return turtle.Instruction{Cmd: turtle.CmdRight, Amount: d} }
+
+// Check that the instructions are the same.
+func checkInstructions(t *testing.T, got, want []turtle.Instruction) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d instructions %v, want %d %v", len(got), got, len(want), want)
+	}
+	for k := range got {
+		if got[k] != want[k] {
+			t.Errorf("instruction %d: got %v, want %v", k, got[k], want[k])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		src string
+		pos logo.Pos
+		msg string
+	}{
+		{"FD 10\n  JUMP 3", logo.Pos{Line: 2, Col: 3}, "I don't know how to JUMP"},
+		{"FD", logo.Pos{Line: 1, Col: 1}, "not enough inputs to FD"},
+		{"FD 10 20", logo.Pos{Line: 1, Col: 7}, "you don't say what to do with"},
+		{"REPEAT 4 FD 10", logo.Pos{Line: 1, Col: 10}, "expected [, found"},
+		{"REPEAT 4 [ FD 10", logo.Pos{Line: 1, Col: 10}, "[ without ]"},
+		{"FD (1 + 2", logo.Pos{Line: 1, Col: 10}, "expected ), found"},
+		{"FD 1.2.3", logo.Pos{Line: 1, Col: 4}, "bad number"},
+		{"FD 10 {", logo.Pos{Line: 1, Col: 7}, "unexpected character"},
+		{"FD :", logo.Pos{Line: 1, Col: 4}, "missing name after :"},
+		{"TO sq :a\nFD :a", logo.Pos{Line: 1, Col: 1}, "TO SQ without END"},
+		{"TO sq\nEND\nTO sq\nEND", logo.Pos{Line: 3, Col: 4}, "procedure SQ is defined twice"},
+		{"TO fd\nEND", logo.Pos{Line: 1, Col: 4}, "FD is already defined"},
+		{"TO a\n  TO b\n  END\nEND", logo.Pos{Line: 2, Col: 3}, "TO inside the procedure A"},
+		{"MAKE size 3", logo.Pos{Line: 1, Col: 6}, "MAKE needs a quoted name"},
+	}
+	for _, c := range cases {
+		_, err := logo.Parse(c.src)
+		var le *logo.Error
+		if !errors.As(err, &le) {
+			t.Errorf("%q: got %v, want a *logo.Error", c.src, err)
+			continue
+		}
+		if le.Pos != c.pos || !strings.Contains(le.Msg, c.msg) {
+			t.Errorf("%q: got %s: %s, want %s: %s", c.src, le.Pos, le.Msg, c.pos, c.msg)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	cases := []struct {
+		src string
+		pos logo.Pos
+		msg string
+	}{
+		{"FD :size", logo.Pos{Line: 1, Col: 4}, "SIZE has no value"},
+		{"FD 1 / 0", logo.Pos{Line: 1, Col: 6}, "division by zero"},
+		{"REPEAT 2.5 [ FD 1 ]", logo.Pos{Line: 1, Col: 1}, "whole number"},
+		{"FD REPCOUNT", logo.Pos{Line: 1, Col: 4}, "REPCOUNT outside of REPEAT"},
+		{"TO loop\n  loop\nEND\nloop", logo.Pos{Line: 2, Col: 3}, "too many nested calls of LOOP"},
+	}
+	for _, c := range cases {
+		err := logo.Run(c.src, &recorder{})
+		var le *logo.Error
+		if !errors.As(err, &le) {
+			t.Errorf("%q: got %v, want a *logo.Error", c.src, err)
+			continue
+		}
+		if le.Pos != c.pos || !strings.Contains(le.Msg, c.msg) {
+			t.Errorf("%q: got %s: %s, want %s: %s", c.src, le.Pos, le.Msg, c.pos, c.msg)
+		}
+	}
+}
+
+func TestTurtleError(t *testing.T) {
+	w := turtle.NewWorld(20, 20)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetPos(10, 10)
+	td.SetBoundary(turtle.BoundaryStop)
+
+	err := logo.Run("FD 5\nFD 100", td)
+	var le *logo.Error
+	if !errors.As(err, &le) || le.Pos != (logo.Pos{Line: 2, Col: 1}) {
+		t.Fatalf("got %v, want an error in 2:1", err)
+	}
+	if !errors.Is(err, turtle.ErrOutOfBounds) {
+		t.Errorf("got %v, want it to wrap ErrOutOfBounds", err)
+	}
+}
+
+func TestProcedures(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want []turtle.Instruction
+	}{
+		{
+			"called before defined",
+			"sq 5\nTO sq :a\n  REPEAT 2 [ FD :a LT 90 ]\nEND",
+			[]turtle.Instruction{fd(5), lt(90), fd(5), lt(90)},
+		},
+		{
+			"parameters are local",
+			"MAKE \"a 1\nTO p :a\n  FD :a\nEND\np 7\nFD :a",
+			[]turtle.Instruction{fd(7), fd(1)},
+		},
+		{
+			"parameters shadow the caller",
+			"TO outer :a\n  inner :a + 1\n  FD :a\nEND\nTO inner :a\n  FD :a\nEND\nouter 3",
+			[]turtle.Instruction{fd(4), fd(3)},
+		},
+		{
+			"assigning a parameter keeps it local",
+			"MAKE \"a 1\nTO p :a\n  MAKE \"a :a * 10\n  FD :a\nEND\np 2\nFD :a",
+			[]turtle.Instruction{fd(20), fd(1)},
+		},
+		{
+			"other variables are global",
+			"TO p\n  MAKE \"n 9\nEND\np\nFD :n",
+			[]turtle.Instruction{fd(9)},
+		},
+		{
+			"recursion with a base case",
+			"TO down :n\n  IF :n = 0 [ STOP ]\n  FD :n\n  down :n - 1\n  RT :n\nEND\ndown 3",
+			[]turtle.Instruction{fd(3), fd(2), fd(1), rt(1), rt(2), rt(3)},
+		},
+		{
+			"stop at the top level",
+			"FD 1 STOP FD 2",
+			[]turtle.Instruction{fd(1)},
+		},
+		{
+			"nested repcount",
+			"REPEAT 2 [ REPEAT 2 [ FD REPCOUNT ] LT REPCOUNT ]",
+			[]turtle.Instruction{fd(1), fd(2), lt(1), fd(1), fd(2), lt(2)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			checkInstructions(t, record(t, c.src), c.want)
+		})
+	}
+}
+
+func TestRunSameAsTurtle(t *testing.T) {
+	const src = `
+; a star and a ring of squares, case does not matter
+to star :size
+  repeat 5 [ fd :size rt 144 ]
+end
+TO square :side
+  REPEAT 4 [ FD :side RT 90 ]
+END
+PU SETXY 40 60 SETH 90 PD
+star 30
+SETPENSIZE 2
+REPEAT 12 [ square 10 + REPCOUNT LT 30 ]
+PU BK -7 PD FD -(3 * 2)
+`
+	run := func(draw func(td *turtle.TurtleDraw)) []byte {
+		w := turtle.NewWorld(120, 120)
+		defer w.Close()
+		td := turtle.NewTurtleDraw(w)
+		td.SetColor(turtle.DarkOrange)
+		draw(td)
+		return w.Image.Pix
+	}
+
+	got := run(func(td *turtle.TurtleDraw) {
+		if err := logo.Run(src, td); err != nil {
+			t.Fatal(err)
+		}
+	})
+	want := run(func(td *turtle.TurtleDraw) {
+		td.PenUp()
+		td.SetPos(40, 60)
+		td.SetHeading(90)
+		td.PenDown()
+		for k := 0; k < 5; k++ {
+			td.Forward(30)
+			td.Right(144)
+		}
+		td.SetSize(2)
+		for r := 1; r <= 12; r++ {
+			for k := 0; k < 4; k++ {
+				td.Forward(float64(10 + r))
+				td.Right(90)
+			}
+			td.Left(30)
+		}
+		td.PenUp()
+		td.Backward(-7)
+		td.PenDown()
+		td.Forward(-6)
+	})
+	if string(got) != string(want) {
+		t.Error("the program draws different pixels than the turtle")
+	}
+}
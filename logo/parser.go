@@ -0,0 +1,328 @@
+package logo
+
+// Parse the source of a Logo program.
+//
+// The procedures can be called before they are defined,
+// the number of arguments of each one is known from its TO line.
+func Parse(src string) (*Program, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks, arity: map[string]int{}}
+	if err := p.scanProcs(); err != nil {
+		return nil, err
+	}
+
+	prog := &Program{Procs: map[string]*Proc{}}
+	for p.peek().kind != tokEOF {
+		if t := p.peek(); t.kind == tokWord && t.text == "TO" {
+			proc, err := p.parseProc()
+			if err != nil {
+				return nil, err
+			}
+			prog.Procs[proc.Name] = proc
+			continue
+		}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		prog.Body = append(prog.Body, s)
+	}
+	return prog, nil
+}
+
+// State of the parser.
+type parser struct {
+	toks  []token
+	i     int
+	arity map[string]int // Number of parameters of the procedures.
+}
+
+// Look at the current token.
+func (p *parser) peek() token {
+	return p.toks[p.i]
+}
+
+// Consume the current token.
+func (p *parser) advance() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// Find the procedures and their number of parameters.
+func (p *parser) scanProcs() error {
+	for i := 0; i < len(p.toks); i++ {
+		t := p.toks[i]
+		if t.kind != tokWord || t.text != "TO" {
+			continue
+		}
+		name := p.toks[i+1]
+		if name.kind != tokWord {
+			return errorf(name.pos, "TO needs the name of the procedure")
+		}
+		if _, ok := primitives[name.text]; ok || isKeyword(name.text) {
+			return errorf(name.pos, "%s is already defined", name.text)
+		}
+		if _, ok := p.arity[name.text]; ok {
+			return errorf(name.pos, "procedure %s is defined twice", name.text)
+		}
+		n := 0
+		for p.toks[i+2+n].kind == tokVar {
+			n++
+		}
+		p.arity[name.text] = n
+	}
+	return nil
+}
+
+// Parse TO name :param ... body END.
+func (p *parser) parseProc() (*Proc, error) {
+	to := p.advance()
+	proc := &Proc{Pos: to.pos, Name: p.advance().text}
+	for p.peek().kind == tokVar {
+		proc.Params = append(proc.Params, p.advance().text)
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokEOF:
+			return nil, errorf(to.pos, "TO %s without END", proc.Name)
+		case t.kind == tokWord && t.text == "END":
+			p.advance()
+			return proc, nil
+		case t.kind == tokWord && t.text == "TO":
+			return nil, errorf(t.pos, "TO inside the procedure %s", proc.Name)
+		}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		proc.Body = append(proc.Body, s)
+	}
+}
+
+// Parse a list of statements in brackets.
+func (p *parser) parseBlock() ([]Stmt, error) {
+	open := p.advance()
+	if open.kind != tokLBracket {
+		return nil, errorf(open.pos, "expected [, found %s", describe(open))
+	}
+	var body []Stmt
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokRBracket:
+			p.advance()
+			return body, nil
+		case t.kind == tokEOF:
+			return nil, errorf(open.pos, "[ without ]")
+		}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+	}
+}
+
+// Parse a statement.
+func (p *parser) parseStmt() (Stmt, error) {
+	t := p.advance()
+	if t.kind != tokWord {
+		return nil, errorf(t.pos, "you don't say what to do with %s", describe(t))
+	}
+
+	switch t.text {
+	case "REPEAT":
+		count, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		body, err := p.parseBlock()
+		if err != nil {
+			return nil, err
+		}
+		return &Repeat{Pos: t.pos, Count: count, Body: body}, nil
+
+	case "IF", "IFELSE":
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		s := &If{Pos: t.pos, Cond: cond}
+		if s.Then, err = p.parseBlock(); err != nil {
+			return nil, err
+		}
+		if t.text == "IFELSE" {
+			if s.Else, err = p.parseBlock(); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+
+	case "MAKE":
+		name := p.advance()
+		if name.kind != tokQuoted {
+			return nil, errorf(name.pos, "MAKE needs a quoted name, like \"size")
+		}
+		value, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &Make{Pos: t.pos, Name: name.text, Value: value}, nil
+
+	case "STOP":
+		return &Stop{Pos: t.pos}, nil
+
+	case "TO", "END":
+		return nil, errorf(t.pos, "%s is only allowed at the top level", t.text)
+	}
+
+	if prim, ok := primitives[t.text]; ok {
+		args, err := p.parseArgs(t, prim.nargs)
+		if err != nil {
+			return nil, err
+		}
+		return &Command{Pos: t.pos, Name: t.text, Args: args}, nil
+	}
+	if n, ok := p.arity[t.text]; ok {
+		args, err := p.parseArgs(t, n)
+		if err != nil {
+			return nil, err
+		}
+		return &Call{Pos: t.pos, Name: t.text, Args: args}, nil
+	}
+	return nil, errorf(t.pos, "I don't know how to %s", t.text)
+}
+
+// Parse the n arguments of a command.
+func (p *parser) parseArgs(cmd token, n int) ([]Expr, error) {
+	args := make([]Expr, 0, n)
+	for len(args) < n {
+		if !startsExpr(p.peek()) {
+			return nil, errorf(cmd.pos, "not enough inputs to %s", cmd.text)
+		}
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+	}
+	return args, nil
+}
+
+// Parse an expression, comparisons bind less than sums,
+// sums less than products.
+func (p *parser) parseExpr() (Expr, error) {
+	return p.parseBinary(0)
+}
+
+// Binary operators, by precedence level.
+var precedence = [][]string{
+	{"=", "<", ">", "<=", ">=", "<>"},
+	{"+", "-"},
+	{"*", "/"},
+}
+
+// Parse the binary operations of the level, and the ones above.
+func (p *parser) parseBinary(level int) (Expr, error) {
+	if level == len(precedence) {
+		return p.parseUnary()
+	}
+	l, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		// a minus like -5 starts the next input
+		if t.kind != tokOp || t.unary || !contains(precedence[level], t.text) {
+			return l, nil
+		}
+		p.advance()
+		r, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &Binary{Pos: t.pos, Op: t.text, L: l, R: r}
+	}
+}
+
+// Parse a number, a variable, a parenthesized expression or a negation.
+func (p *parser) parseUnary() (Expr, error) {
+	t := p.advance()
+	switch {
+	case t.kind == tokOp && t.text == "-":
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Neg{Pos: t.pos, X: x}, nil
+	case t.kind == tokNumber:
+		return &Number{Pos: t.pos, Value: t.num}, nil
+	case t.kind == tokVar:
+		return &Var{Pos: t.pos, Name: t.text}, nil
+	case t.kind == tokWord && t.text == "REPCOUNT":
+		return &RepCount{Pos: t.pos}, nil
+	case t.kind == tokLParen:
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.advance(); c.kind != tokRParen {
+			return nil, errorf(c.pos, "expected ), found %s", describe(c))
+		}
+		return e, nil
+	}
+	return nil, errorf(t.pos, "expected a value, found %s", describe(t))
+}
+
+// Check if the token can start an expression.
+func startsExpr(t token) bool {
+	switch t.kind {
+	case tokNumber, tokVar, tokLParen:
+		return true
+	case tokOp:
+		return t.text == "-"
+	case tokWord:
+		return t.text == "REPCOUNT"
+	}
+	return false
+}
+
+// Check if the word has a meaning of its own.
+func isKeyword(w string) bool {
+	switch w {
+	case "TO", "END", "REPEAT", "IF", "IFELSE", "MAKE", "STOP", "REPCOUNT":
+		return true
+	}
+	return false
+}
+
+// Describe a token in an error message.
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "the end of the program"
+	case tokQuoted:
+		return "\"" + t.text
+	case tokVar:
+		return ":" + t.text
+	}
+	return t.text
+}
+
+// Check if the list contains s.
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package logo
+
+import "github.com/Pitrified/go-turtle"
+
+// A turtle primitive, executed as a single Instruction.
+type primitive struct {
+	cmd   turtle.CmdType
+	nargs int
+}
+
+// The turtle primitives, with their abbreviations.
+//
+// The headings follow the turtle package: 0 is East, counter clockwise.
+var primitives = map[string]primitive{
+	"FORWARD":    {turtle.CmdForward, 1},
+	"FD":         {turtle.CmdForward, 1},
+	"BACK":       {turtle.CmdBackward, 1},
+	"BK":         {turtle.CmdBackward, 1},
+	"LEFT":       {turtle.CmdLeft, 1},
+	"LT":         {turtle.CmdLeft, 1},
+	"RIGHT":      {turtle.CmdRight, 1},
+	"RT":         {turtle.CmdRight, 1},
+	"PENUP":      {turtle.CmdPenUp, 0},
+	"PU":         {turtle.CmdPenUp, 0},
+	"PENDOWN":    {turtle.CmdPenDown, 0},
+	"PD":         {turtle.CmdPenDown, 0},
+	"SETHEADING": {turtle.CmdSetHeading, 1},
+	"SETH":       {turtle.CmdSetHeading, 1},
+	"SETXY":      {turtle.CmdSetPos, 2},
+	"SETPENSIZE": {turtle.CmdSetSize, 1},
+}
+
+// Build the Instruction of a primitive, with the evaluated arguments.
+func (p primitive) instruction(args []float64) turtle.Instruction {
+	i := turtle.Instruction{Cmd: p.cmd}
+	switch p.nargs {
+	case 1:
+		i.Amount = args[0]
+	case 2:
+		i.X, i.Y = args[0], args[1]
+	}
+	return i
+}
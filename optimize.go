@@ -0,0 +1,397 @@
+package turtle
+
+import (
+	"image"
+	"math"
+)
+
+// Optimize an instruction stream for td, returning a shorter one that draws the same image.
+//
+// td is the TurtleDraw that will execute the stream, from its current state:
+// each rewrite is tried on a copy of its Turtle, and kept only if the turtle
+// ends in exactly the same state and the lines paint exactly the same pixels,
+// so the image is identical, with or without the precise mode.
+// Optimize must be the last stage before td: a rewrite checked from one pose
+// can paint other pixels from another, so the stream must not be changed
+// by Repeat, Rotate, Mirror or other transforms after it.
+//
+// Consecutive turns are folded, and the ones that cancel out are removed.
+// With the pen down, consecutive moves are merged in a single line
+// when it paints the same pixels as the pieces, like along the axes.
+// With the pen up, any chain of moves and turns becomes at most
+// a move forward, a turn left, a move and a turn,
+// or nothing if the turtle ends where it started.
+// Redundant PenUp and PenDown are removed.
+// The other commands are passed through, and nothing is merged across them.
+//
+// The moves are kept as they are if the Boundary mode changes the path,
+// and the lines are not merged if painting a pixel twice changes it,
+// with ModeXor or a BlendMode, or if the World is recording the lines,
+// that are scaled before being drawn.
+//
+// A nil td stands for a new TurtleDraw of an unknown World:
+// a turtle from New, with the pen down and no Boundary;
+// the moves are rewritten, but the lines are never merged.
+//
+// The returned channel is closed when in is closed.
+func Optimize(in <-chan Instruction, td *TurtleDraw) <-chan Instruction {
+	out := make(chan Instruction)
+	o := newOptimizer(out, td)
+	go func() {
+		for i := range in {
+			o.add(i)
+		}
+		o.flush()
+		close(out)
+	}()
+	return out
+}
+
+// Accumulate the pending instructions of an Optimize stream.
+type optimizer struct {
+	out chan<- Instruction
+
+	t    *Turtle // Copy of the turtle, after the instructions sent.
+	pen  bool    // Pen down, after the instructions sent.
+	pens []bool  // Pens saved by Push.
+
+	moves bool // The moves can be rewritten.
+	lines bool // The lines can be merged.
+
+	// instructions received and not sent yet:
+	// with the pen down turns then moves, with the pen up any chain of them
+	pending []Instruction
+}
+
+// Create a new optimizer for the TurtleDraw td.
+func newOptimizer(out chan<- Instruction, td *TurtleDraw) *optimizer {
+	if td == nil {
+		// the pixels painted are unknown, only the moves are safe to rewrite
+		return &optimizer{out: out, t: New(), pen: true, moves: true}
+	}
+	t := td.Turtle.clone()
+	t.stack = nil
+	o := &optimizer{out: out, t: t, pen: td.On}
+	for _, dp := range td.stack {
+		t.stack = append(t.stack, dp.pose)
+		o.pens = append(o.pens, dp.pen.On)
+	}
+
+	// like in travel, the unbounded World ignores the Boundary
+	o.moves = td.Boundary == BoundaryNone || td.W.chunks != nil
+	o.lines = o.moves && td.Mode != ModeXor && td.Blend == BlendNormal && !td.W.recording
+	return o
+}
+
+// Add an instruction to the stream.
+func (o *optimizer) add(i Instruction) {
+	switch i.Cmd {
+	case CmdLeft, CmdRight:
+		o.pending = append(o.pending, i)
+
+	case CmdForward, CmdBackward:
+		if !o.moves {
+			o.barrier(i)
+			return
+		}
+		// with the pen down, the turns after a line end it, unless they cancel out
+		if o.pen && !o.dropTrailingTurns() {
+			o.flush()
+		}
+		o.pending = append(o.pending, i)
+
+	case CmdPenUp, CmdPenDown:
+		down := i.Cmd == CmdPenDown
+		if down == o.pen {
+			// redundant
+			return
+		}
+		o.barrier(i)
+		o.pen = down
+
+	case CmdPush:
+		o.barrier(i)
+		o.pens = append(o.pens, o.pen)
+
+	case CmdPop:
+		o.barrier(i)
+		if len(o.pens) > 0 {
+			o.pen = o.pens[len(o.pens)-1]
+			o.pens = o.pens[:len(o.pens)-1]
+		}
+
+	default:
+		o.barrier(i)
+	}
+}
+
+// Send the pending instructions, then i, that can not be merged.
+func (o *optimizer) barrier(i Instruction) {
+	o.flush()
+	o.send(i)
+}
+
+// Remove the turns after the last pending move, if they do not change the heading.
+//
+// Returns false if there are turns after a move that can not be removed.
+func (o *optimizer) dropTrailingTurns() bool {
+	k := len(o.pending)
+	for k > 0 && !isMove(o.pending[k-1]) {
+		k--
+	}
+	if k == 0 || k == len(o.pending) {
+		return true
+	}
+
+	before := o.t.clone()
+	for _, i := range o.pending[:k] {
+		before.DoInstruction(i)
+	}
+	after := before.clone()
+	for _, i := range o.pending[k:] {
+		after.DoInstruction(i)
+	}
+	if !sameState(before, after) {
+		return false
+	}
+	o.pending = o.pending[:k]
+	return true
+}
+
+// Send an instruction, following it with the turtle.
+func (o *optimizer) send(i Instruction) {
+	o.out <- i
+	o.t.DoInstruction(i)
+}
+
+// Send all the pending instructions.
+func (o *optimizer) flush() {
+	if len(o.pending) == 0 {
+		return
+	}
+	if o.pen || !o.flushChain() {
+		o.flushRuns()
+	}
+	o.pending = o.pending[:0]
+}
+
+// Send the pending pen up chain as a jump, false if it does not end in the same state.
+func (o *optimizer) flushChain() bool {
+	// the chain in the frame of the turtle at its start
+	heading, dx, dy := 0.0, 0.0, 0.0
+	for _, i := range o.pending {
+		if isMove(i) {
+			ux, uy := unitVector(heading)
+			dx += moveAmount(i) * ux
+			dy += moveAmount(i) * uy
+		} else {
+			heading = math.Mod(heading+turnAmount(i), 360)
+		}
+	}
+	jump := jumpInstructions(dx, dy, heading)
+	if len(jump) >= len(o.pending) {
+		return false
+	}
+
+	truth := o.t.clone()
+	for _, i := range o.pending {
+		truth.DoInstruction(i)
+	}
+	try := o.t.clone()
+	for _, i := range jump {
+		try.DoInstruction(i)
+	}
+	if !sameState(try, truth) {
+		return false
+	}
+	for _, i := range jump {
+		o.send(i)
+	}
+	return true
+}
+
+// Send the pending instructions, folding each run of turns
+// and merging each run of moves, where the result is the same.
+func (o *optimizer) flushRuns() {
+	for k := 0; k < len(o.pending); {
+		if isMove(o.pending[k]) {
+			k = o.flushMoves(k)
+		} else {
+			k = o.flushTurns(k)
+		}
+	}
+}
+
+// Send the run of turns starting at k, returning the index after it.
+func (o *optimizer) flushTurns(k int) int {
+	truth := o.t.clone()
+	deg := 0.0
+	end := k
+	for ; end < len(o.pending) && !isMove(o.pending[end]); end++ {
+		truth.DoInstruction(o.pending[end])
+		deg += turnAmount(o.pending[end])
+	}
+
+	try := o.t.clone()
+	turn, ok := turnInstruction(deg)
+	if ok {
+		try.DoInstruction(turn)
+	}
+	switch {
+	case !sameState(try, truth):
+		for _, i := range o.pending[k:end] {
+			o.send(i)
+		}
+	case ok:
+		o.send(turn)
+	}
+	return end
+}
+
+// Send the run of moves starting at k, merging the longest prefixes
+// that give the same result, returning the index after the run.
+func (o *optimizer) flushMoves(k int) int {
+	for k < len(o.pending) && isMove(o.pending[k]) {
+		// the first move as it is, then grow it while the result is the same
+		truth := o.t.clone()
+		var painted map[image.Point]bool
+		if o.pen {
+			painted = make(map[image.Point]bool)
+		}
+		x0, y0 := truth.X, truth.Y
+		o.follow(truth, o.pending[k], painted)
+		dist := moveAmount(o.pending[k])
+
+		end := k + 1
+		for ; end < len(o.pending) && isMove(o.pending[end]); end++ {
+			if o.pen && !o.lines {
+				break
+			}
+			o.follow(truth, o.pending[end], painted)
+			d := dist + moveAmount(o.pending[end])
+			try := o.t.clone()
+			try.Forward(d)
+			if !sameState(try, truth) || (o.pen && !samePixels(x0, y0, try.X, try.Y, painted)) {
+				break
+			}
+			dist = d
+		}
+
+		if end == k+1 {
+			o.send(o.pending[k])
+		} else {
+			o.send(moveInstruction(dist))
+		}
+		k = end
+	}
+	return k
+}
+
+// Move the turtle t, adding the pixels of the line to painted, if not nil.
+func (o *optimizer) follow(t *Turtle, i Instruction, painted map[image.Point]bool) {
+	x0, y0 := t.X, t.Y
+	t.DoInstruction(i)
+	if painted == nil {
+		return
+	}
+	linePixels(int(x0), int(y0), int(t.X), int(t.Y), func(x, y int) {
+		painted[image.Point{x, y}] = true
+	})
+}
+
+// Check if the line from (x0, y0) to (x1, y1) paints exactly the pixels painted.
+func samePixels(x0, y0, x1, y1 float64, painted map[image.Point]bool) bool {
+	n := 0
+	same := true
+	linePixels(int(x0), int(y0), int(x1), int(y1), func(x, y int) {
+		n++
+		if !painted[image.Point{x, y}] {
+			same = false
+		}
+	})
+	return same && n == len(painted)
+}
+
+// Check if two turtles are in exactly the same state, for the next moves.
+func sameState(a, b *Turtle) bool {
+	if !sameFloat(a.X, b.X) || !sameFloat(a.Y, b.Y) || !sameFloat(a.Deg, b.Deg) {
+		return false
+	}
+	if !a.precise {
+		return true
+	}
+	aX, aY := a.pendingErr()
+	bX, bY := b.pendingErr()
+	return sameFloat(aX, bX) && sameFloat(aY, bY)
+}
+
+// Check if two floats have the same bits.
+func sameFloat(a, b float64) bool {
+	return math.Float64bits(a) == math.Float64bits(b)
+}
+
+// Get the instructions to move by (dx, dy) and turn by deg degrees,
+// in the frame of the turtle: a move forward, a turn left, a move and a turn.
+//
+// Moving along the two axes keeps a turtle on the integer lattice.
+func jumpInstructions(dx, dy, deg float64) []Instruction {
+	var is []Instruction
+	if dx != 0 {
+		is = append(is, moveInstruction(dx))
+	}
+	if dy != 0 {
+		is = append(is, Instruction{Cmd: CmdLeft, Amount: 90}, moveInstruction(dy))
+		deg -= 90
+	}
+	if i, ok := turnInstruction(deg); ok {
+		is = append(is, i)
+	}
+	return is
+}
+
+// Get the instruction to turn by deg degrees, the short way around,
+// false if the heading does not change.
+func turnInstruction(deg float64) (Instruction, bool) {
+	deg = math.Mod(deg, 360)
+	if deg > 180 {
+		deg -= 360
+	} else if deg < -180 {
+		deg += 360
+	}
+	switch {
+	case deg > 0:
+		return Instruction{Cmd: CmdLeft, Amount: deg}, true
+	case deg < 0:
+		return Instruction{Cmd: CmdRight, Amount: -deg}, true
+	}
+	return Instruction{}, false
+}
+
+// Get the instruction to move by d, backward if d is negative.
+func moveInstruction(d float64) Instruction {
+	if d < 0 {
+		return Instruction{Cmd: CmdBackward, Amount: -d}
+	}
+	return Instruction{Cmd: CmdForward, Amount: d}
+}
+
+// Check if an instruction is a move forward or backward.
+func isMove(i Instruction) bool {
+	return i.Cmd == CmdForward || i.Cmd == CmdBackward
+}
+
+// Get the turn of an instruction, positive to the left.
+func turnAmount(i Instruction) float64 {
+	if i.Cmd == CmdRight {
+		return -i.Amount
+	}
+	return i.Amount
+}
+
+// Get the move of an instruction, positive forward.
+func moveAmount(i Instruction) float64 {
+	if i.Cmd == CmdBackward {
+		return -i.Amount
+	}
+	return i.Amount
+}
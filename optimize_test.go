@@ -0,0 +1,183 @@
+package turtle_test
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/fractal"
+)
+
+// Generate the instructions of a drawing on a channel, closing it at the end.
+type generator func(instructions chan<- turtle.Instruction)
+
+// Walk around randomly, with the pen going up and down and some branches.
+func randomWalk(seed int64) generator {
+	return func(instructions chan<- turtle.Instruction) {
+		r := rand.New(rand.NewSource(seed))
+		angles := []float64{30, 45, 90, 90, 90, 180, 25.7}
+		depth := 0
+		for k := 0; k < 3000; k++ {
+			var i turtle.Instruction
+			switch n := r.Intn(20); {
+			case n < 8:
+				i = turtle.Instruction{Cmd: turtle.CmdForward, Amount: float64(r.Intn(30)) + r.Float64()*float64(r.Intn(2))}
+			case n < 9:
+				i = turtle.Instruction{Cmd: turtle.CmdBackward, Amount: float64(r.Intn(30))}
+			case n < 12:
+				i = turtle.Instruction{Cmd: turtle.CmdLeft, Amount: angles[r.Intn(len(angles))]}
+			case n < 15:
+				i = turtle.Instruction{Cmd: turtle.CmdRight, Amount: angles[r.Intn(len(angles))]}
+			case n < 16:
+				i = turtle.Instruction{Cmd: turtle.CmdPenUp}
+			case n < 18:
+				i = turtle.Instruction{Cmd: turtle.CmdPenDown}
+			case n < 19:
+				i = turtle.Instruction{Cmd: turtle.CmdPush}
+				depth++
+			default:
+				if depth == 0 {
+					continue
+				}
+				i = turtle.Instruction{Cmd: turtle.CmdPop}
+				depth--
+			}
+			instructions <- i
+		}
+		close(instructions)
+	}
+}
+
+// Send a fixed list of instructions.
+func fixed(is ...turtle.Instruction) generator {
+	return func(instructions chan<- turtle.Instruction) {
+		for _, i := range is {
+			instructions <- i
+		}
+		close(instructions)
+	}
+}
+
+// Draw the instructions with a fresh TurtleDraw, optimizing them if opt,
+// returning the pixels and the number of instructions executed.
+func render(t *testing.T, gen generator, setup func(td *turtle.TurtleDraw), opt bool) ([]byte, int) {
+	t.Helper()
+	w := turtle.NewWorld(400, 400)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetPos(200, 200)
+	td.PenDown()
+	td.SetColor(turtle.DarkOrange)
+	setup(td)
+
+	instructions := make(chan turtle.Instruction)
+	go gen(instructions)
+	var stream <-chan turtle.Instruction = instructions
+	if opt {
+		stream = turtle.Optimize(instructions, td)
+	}
+	n, err := turtle.Run(context.Background(), td, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w.Image.Pix, n
+}
+
+func TestOptimizeSamePixels(t *testing.T) {
+	gens := map[string]generator{
+		"hilbert": func(c chan<- turtle.Instruction) { fractal.GenerateHilbert(5, c, 11) },
+		"dragon":  func(c chan<- turtle.Instruction) { fractal.GenerateDragon(10, c, 3.3) },
+		"tri":     func(c chan<- turtle.Instruction) { fractal.GenerateSierpinskiTriangle(5, c, 9.7) },
+		"arrow":   func(c chan<- turtle.Instruction) { fractal.GenerateSierpinskiArrowhead(5, c, 5) },
+		"plant":   func(c chan<- turtle.Instruction) { fractal.GeneratePlant(4, c, 4.1) },
+		"walk1":   randomWalk(1),
+		"walk2":   randomWalk(2),
+	}
+	setups := map[string]func(td *turtle.TurtleDraw){
+		"plain":   func(td *turtle.TurtleDraw) {},
+		"precise": func(td *turtle.TurtleDraw) { td.SetPrecise(true) },
+		"offset": func(td *turtle.TurtleDraw) {
+			td.SetPos(200.37, 199.91)
+			td.SetHeading(33.3)
+		},
+		"thick": func(td *turtle.TurtleDraw) {
+			td.SetPrecise(true)
+			td.SetSize(4)
+		},
+		"xor": func(td *turtle.TurtleDraw) {
+			td.SetPrecise(true)
+			td.SetMode(turtle.ModeXor)
+		},
+		"add": func(td *turtle.TurtleDraw) {
+			td.SetSize(3)
+			td.SetBlend(turtle.BlendAdd)
+		},
+	}
+	for gn, gen := range gens {
+		for sn, setup := range setups {
+			want, n := render(t, gen, setup, false)
+			got, nOpt := render(t, gen, setup, true)
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s %s: the optimized stream draws different pixels", gn, sn)
+			}
+			if nOpt > n {
+				t.Errorf("%s %s: the optimized stream is longer, %d > %d", gn, sn, nOpt, n)
+			}
+		}
+	}
+}
+
+func TestOptimizeMergesLines(t *testing.T) {
+	f := turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10}
+	l := turtle.Instruction{Cmd: turtle.CmdLeft, Amount: 90}
+	r := turtle.Instruction{Cmd: turtle.CmdRight, Amount: 90}
+	gen := fixed(f, f, f, l, l, r, f, f, l, r, f)
+
+	want, _ := render(t, gen, func(td *turtle.TurtleDraw) {}, false)
+	got, n := render(t, gen, func(td *turtle.TurtleDraw) {}, true)
+	if !bytes.Equal(got, want) {
+		t.Error("the optimized stream draws different pixels")
+	}
+	// F 30, L 90, F 30
+	if n != 3 {
+		t.Errorf("got %d instructions, want 3", n)
+	}
+
+	// painting twice with xor cancels, the lines are kept
+	xor := func(td *turtle.TurtleDraw) { td.SetMode(turtle.ModeXor) }
+	want, _ = render(t, gen, xor, false)
+	got, n = render(t, gen, xor, true)
+	if !bytes.Equal(got, want) {
+		t.Error("xor: the optimized stream draws different pixels")
+	}
+	if n != 7 {
+		t.Errorf("xor: got %d instructions, want 7", n)
+	}
+}
+
+func TestOptimizeShortens(t *testing.T) {
+	gen := func(c chan<- turtle.Instruction) { fractal.GenerateHilbert(5, c, 8) }
+	precise := func(td *turtle.TurtleDraw) { td.SetPrecise(true) }
+	_, n := render(t, gen, precise, false)
+	_, nOpt := render(t, gen, precise, true)
+	if nOpt*4 > n*3 {
+		t.Errorf("the hilbert curve went from %d to %d instructions only", n, nOpt)
+	}
+}
+
+func TestOptimizeNil(t *testing.T) {
+	f := turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10}
+	l := turtle.Instruction{Cmd: turtle.CmdLeft, Amount: 90}
+	r := turtle.Instruction{Cmd: turtle.CmdRight, Amount: 90}
+	in := make(chan turtle.Instruction)
+	go fixed(f, f, l, l, r, f, l, r, f)(in)
+
+	var got []turtle.Instruction
+	for i := range turtle.Optimize(in, nil) {
+		got = append(got, i)
+	}
+	// the turns are folded, the lines are not merged without a World
+	checkInstructions(t, got, []turtle.Instruction{f, f, l, f, f})
+}
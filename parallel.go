@@ -0,0 +1,103 @@
+package turtle
+
+import (
+	"image"
+	"runtime"
+	"sync"
+)
+
+// Draw the lines on the image, rasterizing it in parallel.
+//
+// The image is split in horizontal bands, each line is binned in the bands it touches,
+// and a pool of workers goroutines draws the bands.
+// Use workers <= 0 to have one worker per CPU.
+//
+// The result is the same as DrawLines:
+// each pixel belongs to a single band, and each band draws its lines in order.
+// RenderFit and the redraw after Undo use it too; the lines of a TurtleDraw
+// are drawn one at a time as they arrive, and are not rasterized in parallel.
+func (w *World) DrawLinesParallel(lines []Line, workers int) {
+	w.run(func() {
+		w.drawLinesParallel(lines, workers)
+		w.remember(lines)
+	})
+}
+
+// Draw the lines on the image in parallel, from the listen goroutine.
+func (w *World) drawLinesParallel(lines []Line, workers int) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	// the chunks of an unbounded World are created while drawing,
+	// and an empty World has no bands to split
+	if workers == 1 || len(lines) == 0 || w.chunks != nil || w.Height <= 0 {
+		w.drawLines(lines)
+		return
+	}
+
+	// a few bands per worker to balance the load
+	nBands := 4 * workers
+	if nBands > w.Height {
+		nBands = w.Height
+	}
+	bandH := (w.Height + nBands - 1) / nBands
+	nBands = (w.Height + bandH - 1) / bandH
+
+	bins := make([][]int, nBands)
+	for i, l := range lines {
+		b := lineRect(l, w.Height)
+		b0 := floorDiv(b.Min.Y, bandH)
+		b1 := floorDiv(b.Max.Y, bandH)
+		if b0 < 0 {
+			b0 = 0
+		}
+		if b1 > nBands-1 {
+			b1 = nBands - 1
+		}
+		for band := b0; band <= b1; band++ {
+			bins[band] = append(bins[band], i)
+		}
+	}
+
+	parallelDo(nBands, workers, func(band int) {
+		rect := image.Rect(0, band*bandH, w.Width, (band+1)*bandH)
+
+		// one raster for each layer, clipped to the band
+		rasters := make(map[string]*raster)
+		for _, i := range bins[band] {
+			l := lines[i]
+			r, ok := rasters[l.Layer]
+			if !ok {
+				img := w.layerImage(l.Layer)
+				if img != nil {
+					sub := img.SubImage(rect).(*image.RGBA)
+					r = &raster{img: sub, height: w.Height, bg: w.layerBackground(l.Layer), band: true}
+				}
+				rasters[l.Layer] = r
+			}
+			if r != nil {
+				r.drawLine(l)
+			}
+		}
+	})
+}
+
+// Call f for each index in [0, n), using a pool of workers goroutines.
+func parallelDo(n, workers int, f func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for k := 0; k < workers; k++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,73 @@
+package turtle_test
+
+import (
+	"bytes"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Create random lines, many crossing each other and the borders.
+func randomLines(seed int64, n int, style func(r *rand.Rand) turtle.PenStyle) []turtle.Line {
+	r := rand.New(rand.NewSource(seed))
+	lines := make([]turtle.Line, n)
+	for i := range lines {
+		lines[i] = turtle.Line{
+			X0:    r.Float64()*260 - 30,
+			Y0:    r.Float64()*220 - 30,
+			X1:    r.Float64()*260 - 30,
+			Y1:    r.Float64()*220 - 30,
+			Style: style(r),
+		}
+		if r.Intn(4) == 0 {
+			lines[i].Layer = "top"
+		}
+	}
+	return lines
+}
+
+// Draw the lines with DrawLines and DrawLinesParallel, returning both images.
+func drawBoth(t *testing.T, lines []turtle.Line) (seq, par []byte) {
+	t.Helper()
+	draw := func(parallel bool) []byte {
+		w := turtle.NewWorld(200, 160)
+		defer w.Close()
+		if _, err := w.AddLayer("top"); err != nil {
+			t.Fatal(err)
+		}
+		if parallel {
+			w.DrawLinesParallel(lines, 4)
+		} else {
+			w.DrawLines(lines)
+		}
+		return w.Composite().Pix
+	}
+	return draw(false), draw(true)
+}
+
+func TestDrawLinesParallelSame(t *testing.T) {
+	modes := map[string]func(r *rand.Rand) turtle.PenStyle{
+		"xor": func(r *rand.Rand) turtle.PenStyle {
+			c := color.RGBA{uint8(r.Intn(256)), uint8(r.Intn(256)), uint8(r.Intn(256)), 255}
+			return turtle.PenStyle{Color: c, Size: 1 + r.Intn(7), Mode: turtle.ModeXor}
+		},
+		"add": func(r *rand.Rand) turtle.PenStyle {
+			c := color.NRGBA{uint8(r.Intn(256)), uint8(r.Intn(256)), uint8(r.Intn(256)), uint8(r.Intn(256))}
+			return turtle.PenStyle{Color: c, Size: 1 + r.Intn(7), Blend: turtle.BlendAdd}
+		},
+	}
+	for name, style := range modes {
+		seq, par := drawBoth(t, randomLines(1, 200, style))
+		if !bytes.Equal(seq, par) {
+			t.Errorf("%s: the parallel image is different", name)
+		}
+	}
+}
+
+func TestDrawLinesParallelEmptyWorld(t *testing.T) {
+	w := turtle.NewWorld(10, 0)
+	defer w.Close()
+	w.DrawLinesParallel([]turtle.Line{{X1: 5, Y1: 5, Style: turtle.PenStyle{Color: turtle.White}}}, 4)
+}
@@ -5,11 +5,32 @@ import (
 	"image/color"
 )
 
+// How the Pen changes the pixels it touches.
+type PenMode byte
+
+const (
+	ModeDraw   PenMode = iota // Paint with the Pen color.
+	ModeErase                 // Restore the background.
+	ModeXor                   // Xor with the Pen color, drawing twice cancels.
+	ModeBehind                // Paint only the pixels that are still background.
+)
+
 // A simple Pen.
 type Pen struct {
+	Color     color.Color // Line color.
+	FillColor color.Color // Fill color for stamps, Color if nil.
+	Size      int         // Line width.
+	On        bool        // State of the Pen.
+	Mode      PenMode     // How to paint.
+	Blend     BlendMode   // How to mix the color with the image.
+}
+
+// The style of a Pen, copied in each Line.
+type PenStyle struct {
 	Color color.Color // Line color.
 	Size  int         // Line width.
-	On    bool        // State of the Pen.
+	Mode  PenMode     // How to paint.
+	Blend BlendMode   // How to mix the color with the image.
 }
 
 // Create a new Pen.
@@ -44,11 +65,38 @@ func (p *Pen) SetColor(c color.Color) {
 	p.Color = c
 }
 
+// Change the Pen fill color.
+func (p *Pen) SetFillColor(c color.Color) {
+	p.FillColor = c
+}
+
 // Change the Pen size.
 func (p *Pen) SetSize(s int) {
 	p.Size = s
 }
 
+// Get a snapshot of the current Pen style.
+func (p *Pen) Style() PenStyle {
+	return PenStyle{Color: p.Color, Size: p.Size, Mode: p.Mode, Blend: p.Blend}
+}
+
+// Change the Pen mode.
+//
+// With ModeXor the last pixel of each line is not painted,
+// so that the pixel shared by two consecutive lines of a path is toggled once,
+// but the squares of thick lines still overlap around the joints.
+func (p *Pen) SetMode(m PenMode) {
+	p.Mode = m
+}
+
+// Change the Pen blend mode.
+//
+// The blend is applied once per pixel for each line,
+// also for thick lines where the squares overlap.
+func (p *Pen) SetBlend(b BlendMode) {
+	p.Blend = b
+}
+
 var _ fmt.Stringer = &Pen{}
 
 // Write the Pen state.
@@ -0,0 +1,145 @@
+package turtle
+
+import (
+	"bufio"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// Max size of the data in a single IDAT chunk.
+const pngChunkSize = 1 << 16
+
+// Writes a RGBA PNG one row at a time,
+// without keeping the whole image in memory.
+//
+// The image/png encoder needs the full image,
+// which is not an option for gigapixel outputs.
+type pngStreamWriter struct {
+	w      *bufio.Writer
+	idat   *pngChunkWriter
+	z      *zlib.Writer
+	width  int
+	height int
+	rows   int
+
+	cur, prev []byte // Filtered row and previous raw row.
+}
+
+// Start a new PNG of the requested size, writing the header.
+func newPNGStreamWriter(w io.Writer, width, height int) (*pngStreamWriter, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write([]byte("\x89PNG\r\n\x1a\n")); err != nil {
+		return nil, err
+	}
+
+	// 8 bit depth, color type 6 (RGBA), default compression/filter, no interlace
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], uint32(width))
+	binary.BigEndian.PutUint32(ihdr[4:8], uint32(height))
+	ihdr[8] = 8
+	ihdr[9] = 6
+	if err := writePNGChunk(bw, "IHDR", ihdr); err != nil {
+		return nil, err
+	}
+
+	idat := &pngChunkWriter{w: bw}
+	return &pngStreamWriter{
+		w:      bw,
+		idat:   idat,
+		z:      zlib.NewWriter(idat),
+		width:  width,
+		height: height,
+		cur:    make([]byte, 1+4*width),
+		prev:   make([]byte, 4*width),
+	}, nil
+}
+
+// Write the next row of pixels, 4 bytes per pixel, non premultiplied.
+func (pw *pngStreamWriter) WriteRow(pix []byte) error {
+	if pw.rows >= pw.height {
+		return errors.New("turtle: too many rows in PNG")
+	}
+	pw.rows++
+
+	// use the Up filter, cheap and effective on large flat areas
+	pw.cur[0] = 2
+	for i, v := range pix {
+		pw.cur[i+1] = v - pw.prev[i]
+	}
+	copy(pw.prev, pix)
+	_, err := pw.z.Write(pw.cur)
+	return err
+}
+
+// Finish the image, writing the trailing chunks.
+func (pw *pngStreamWriter) Close() error {
+	if pw.rows != pw.height {
+		return errors.New("turtle: missing rows in PNG")
+	}
+	if err := pw.z.Close(); err != nil {
+		return err
+	}
+	if err := pw.idat.Flush(); err != nil {
+		return err
+	}
+	if err := writePNGChunk(pw.w, "IEND", nil); err != nil {
+		return err
+	}
+	return pw.w.Flush()
+}
+
+// Splits the compressed stream in IDAT chunks.
+type pngChunkWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+// Buffer the data, writing a chunk when it is full.
+func (cw *pngChunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := pngChunkSize - len(cw.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		cw.buf = append(cw.buf, p[:k]...)
+		p = p[k:]
+		if len(cw.buf) == pngChunkSize {
+			if err := cw.Flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Write the buffered data as a chunk.
+func (cw *pngChunkWriter) Flush() error {
+	if len(cw.buf) == 0 {
+		return nil
+	}
+	err := writePNGChunk(cw.w, "IDAT", cw.buf)
+	cw.buf = cw.buf[:0]
+	return err
+}
+
+// Write a PNG chunk: length, type, data and CRC.
+func writePNGChunk(w io.Writer, name string, data []byte) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+	for _, b := range [][]byte{header, data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
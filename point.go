@@ -0,0 +1,14 @@
+package turtle
+
+import "math"
+
+// A point on the cartesian plane.
+type Point struct {
+	X, Y float64
+}
+
+// Rotate the point counter clockwise by deg degrees around the origin.
+func (p Point) Rotate(deg float64) Point {
+	s, c := math.Sincos(Deg2rad(deg))
+	return Point{p.X*c - p.Y*s, p.X*s + p.Y*c}
+}
@@ -0,0 +1,142 @@
+package turtle
+
+import "math"
+
+// Largest denominator of the fractions of a turn snapped by the precise mode.
+const maxTurnDenom = 1000
+
+// Tolerance, in turns, to consider a heading an exact fraction of a turn.
+const turnTolerance = 1e-9
+
+// Enable or disable the precise mode.
+//
+// In precise mode the turtle does not drift on long paths:
+// headings that are fractions of a turn (like 90, 60 or 360/7 degrees)
+// are snapped to the exact value and moved along exact unit vectors,
+// so that 90 degree turns stay on the integer lattice,
+// and the positions are summed with a compensation of the rounding errors.
+func (t *Turtle) SetPrecise(on bool) {
+	t.precise = on
+	t.errX, t.errY = 0, 0
+	if on {
+		t.Deg = snapDeg(t.Deg)
+	}
+}
+
+// Check if the precise mode is enabled.
+func (t *Turtle) Precise() bool {
+	return t.precise
+}
+
+// Snap the heading to a fraction of a turn, in precise mode.
+func (t *Turtle) snap() {
+	if t.precise {
+		t.Deg = snapDeg(t.Deg)
+	}
+}
+
+// Move the Turtle forward by dist, in precise mode.
+func (t *Turtle) forwardPrecise(dist float64) {
+	ux, uy := unitVector(t.Deg)
+	t.stepPrecise(dist*ux, dist*uy)
+}
+
+// Move the Turtle by (dx, dy), compensating the rounding errors.
+func (t *Turtle) stepPrecise(dx, dy float64) {
+	// the error is stale if the position was changed from outside
+	errX, errY := t.pendingErr()
+	t.X, t.errX = twoSum(t.X, dx+errX)
+	t.Y, t.errY = twoSum(t.Y, dy+errY)
+	t.lastX, t.lastY = t.X, t.Y
+}
+
+// Get the rounding error that the next move in precise mode compensates.
+func (t *Turtle) pendingErr() (float64, float64) {
+	if t.X != t.lastX || t.Y != t.lastY {
+		return 0, 0
+	}
+	return t.errX, t.errY
+}
+
+// Add a and b, returning the rounded sum and its rounding error.
+func twoSum(a, b float64) (float64, float64) {
+	s := a + b
+	bb := s - a
+	return s, (a - (s - bb)) + (b - bb)
+}
+
+// Find the fraction of a turn p/q close to deg degrees,
+// with 0 <= p < q <= maxTurnDenom, using the continued fraction expansion.
+func turnFraction(deg float64) (p, q int64, ok bool) {
+	f := normDeg(deg) / 360
+	x := f
+	// convergents h/k of the continued fraction
+	h0, h1 := int64(0), int64(1)
+	k0, k1 := int64(1), int64(0)
+	for i := 0; i < 64; i++ {
+		a := math.Floor(x)
+		h0, h1 = h1, int64(a)*h1+h0
+		k0, k1 = k1, int64(a)*k1+k0
+		if k1 > maxTurnDenom {
+			return 0, 0, false
+		}
+		if math.Abs(f-float64(h1)/float64(k1)) <= turnTolerance {
+			return h1 % k1, k1, true
+		}
+		if x == a {
+			break
+		}
+		x = 1 / (x - a)
+	}
+	return 0, 0, false
+}
+
+// Snap an angle in degrees to the exact fraction of a turn it is close to.
+func snapDeg(deg float64) float64 {
+	p, q, ok := turnFraction(deg)
+	if !ok {
+		return normDeg(deg)
+	}
+	return 360 * float64(p) / float64(q)
+}
+
+// Get the unit vector of the heading deg.
+//
+// The vectors of fractions of a turn are exact: the quarter turns are integers,
+// and symmetric headings have symmetric vectors.
+func unitVector(deg float64) (float64, float64) {
+	p, q, ok := turnFraction(deg)
+	if !ok {
+		rad := Deg2rad(deg)
+		return math.Cos(rad), math.Sin(rad)
+	}
+
+	// split the angle in quarter turns and a remainder rem/(4q) of a turn
+	quarters := (4 * p) / q
+	rem := (4 * p) % q
+
+	// compute the remainder in the first octant, mirror it if needed
+	var c, s float64
+	switch {
+	case rem == 0:
+		c, s = 1, 0
+	case 3*rem == q:
+		c, s = math.Sqrt(3)/2, 0.5
+	case 2*rem == q:
+		c, s = math.Sqrt(0.5), math.Sqrt(0.5)
+	case 3*rem == 2*q:
+		c, s = 0.5, math.Sqrt(3)/2
+	case 2*rem < q:
+		a := math.Pi / 2 * float64(rem) / float64(q)
+		c, s = math.Cos(a), math.Sin(a)
+	default:
+		a := math.Pi / 2 * float64(q-rem) / float64(q)
+		c, s = math.Sin(a), math.Cos(a)
+	}
+
+	// rotate by the quarter turns, exactly
+	for i := int64(0); i < quarters; i++ {
+		c, s = -s, c
+	}
+	return c, s
+}
@@ -0,0 +1,95 @@
+package turtle_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/fractal"
+)
+
+// Walk a Hilbert curve with unit segments, returning the turtle at the end.
+func walkHilbert(level int, precise bool) *turtle.Turtle {
+	t := turtle.New()
+	t.SetPrecise(precise)
+	instructions := make(chan turtle.Instruction)
+	go fractal.GenerateHilbert(level, instructions, 1)
+	for i := range instructions {
+		t.DoInstruction(i)
+	}
+	return t
+}
+
+// Walk around a regular polygon with n sides many times,
+// returning how far from the start the turtle ends.
+func walkPolygon(n, laps int, precise bool) (dx, dy float64) {
+	t := turtle.New()
+	t.SetPrecise(precise)
+	t.SetPos(100, 100)
+	for k := 0; k < n*laps; k++ {
+		t.Forward(13)
+		t.Left(360 / float64(n))
+	}
+	return t.X - 100, t.Y - 100
+}
+
+func TestPreciseHilbert(t *testing.T) {
+	// 65535 segments, from the bottom left to the bottom right corner
+	tu := walkHilbert(8, true)
+	if tu.X != 255 || tu.Y != 0 || tu.Deg != 0 {
+		t.Errorf("precise: got (%v, %v) heading %v, want (255, 0) heading 0", tu.X, tu.Y, tu.Deg)
+	}
+	if tu.X != math.Trunc(tu.X) || tu.Y != math.Trunc(tu.Y) {
+		t.Errorf("precise: (%v, %v) is off the lattice", tu.X, tu.Y)
+	}
+
+	// without the precise mode the cosine of 90 degrees is not 0
+	tu = walkHilbert(8, false)
+	if tu.X == math.Trunc(tu.X) && tu.Y == math.Trunc(tu.Y) {
+		t.Errorf("plain: (%v, %v) did not drift off the lattice", tu.X, tu.Y)
+	}
+}
+
+func TestPrecisePolygon(t *testing.T) {
+	drift := false
+	for _, n := range []int{3, 5, 6, 7, 12} {
+		if dx, dy := walkPolygon(n, 1000, true); dx != 0 || dy != 0 {
+			t.Errorf("precise %d-gon: ended (%v, %v) from the start", n, dx, dy)
+		}
+		if dx, dy := walkPolygon(n, 1000, false); dx != 0 || dy != 0 {
+			drift = true
+		}
+	}
+	if !drift {
+		t.Error("plain: no polygon drifted from the start")
+	}
+}
+
+func TestPreciseStrafe(t *testing.T) {
+	for _, n := range []int{3, 4, 7} {
+		tu := turtle.New()
+		tu.SetPrecise(true)
+		tu.SetPos(100, 100)
+		// strafe around the polygon, facing its center
+		for k := 0; k < n*1000; k++ {
+			tu.StrafeRight(13)
+			tu.Left(360 / float64(n))
+		}
+		if tu.X != 100 || tu.Y != 100 {
+			t.Errorf("%d-gon: ended in (%v, %v), want (100, 100)", n, tu.X, tu.Y)
+		}
+	}
+
+	// a quarter turn strafes on the lattice
+	w := turtle.NewWorld(50, 50)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetPrecise(true)
+	td.SetPos(10, 10)
+	td.Left(90)
+	td.StrafeLeft(7)
+	td.StrafeRight(2)
+	if td.X != 5 || td.Y != 10 {
+		t.Errorf("got (%v, %v), want (5, 10)", td.X, td.Y)
+	}
+}
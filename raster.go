@@ -0,0 +1,278 @@
+package turtle
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Draws lines on an image, using cartesian coordinates.
+type raster struct {
+	img    draw.Image
+	height int         // Height of the whole image, to flip the y axis.
+	bg     image.Image // Background restored by the eraser, nil if transparent.
+	band   bool        // Skip the pixels of the lines that can not reach img.
+
+	seen map[image.Point]bool // Pixels already painted by the current line.
+}
+
+// Draw a line on the image.
+func (r *raster) drawLine(l Line) {
+	// nothing to draw with
+	if l.Style.Color == nil && l.Style.Mode != ModeErase {
+		return
+	}
+
+	// thick lines paint the same pixel many times,
+	// which is only fine if painting is idempotent
+	r.seen = nil
+	if l.Style.Mode == ModeXor || l.Style.Blend != BlendNormal {
+		r.seen = make(map[image.Point]bool)
+	}
+
+	// with xor the last pixel is left to the next line of the path,
+	// or the pixel shared by the two lines would be toggled twice
+	x0, y0, x1, y1 := int(l.X0), int(l.Y0), int(l.X1), int(l.Y1)
+	skipLast := l.Style.Mode == ModeXor && (x0 != x1 || y0 != y1)
+
+	visit := func(x, y int) {
+		if skipLast && x == x1 && y == y1 {
+			return
+		}
+		r.setPoint(x, y, l.Style)
+	}
+	if r.band {
+		linePixelsIn(x0, y0, x1, y1, r.centers(l.Style.Size), visit)
+	} else {
+		linePixels(x0, y0, x1, y1, visit)
+	}
+}
+
+// Get the rectangle of the points, in cartesian coordinates,
+// whose square of the requested size touches the image.
+func (r *raster) centers(size int) image.Rectangle {
+	b := r.img.Bounds()
+	before, half := penSquare(size)
+	// the rows of the image, flipped
+	y0 := r.height - b.Max.Y
+	y1 := r.height - b.Min.Y
+	return image.Rect(b.Min.X-half, y0-half, b.Max.X+before, y1+before)
+}
+
+// Call f on each pixel of the line from (x0, y0) to (x1, y1), in cartesian coordinates.
+//
+// The pixels are visited once each, from the lower end for the straight lines.
+func linePixels(x0, y0, x1, y1 int, f func(x, y int)) {
+	// line is vertical
+	if x0 == x1 {
+		if y0 > y1 {
+			y1, y0 = y0, y1
+		}
+		for i := y0; i <= y1; i++ {
+			f(x0, i)
+		}
+		return
+	}
+
+	// line is horizontal
+	if y0 == y1 {
+		if x0 > x1 {
+			x1, x0 = x0, x1
+		}
+		for i := x0; i <= x1; i++ {
+			f(i, y0)
+		}
+		return
+	}
+
+	// line is diagonal, draw it with Bresenham algo
+	dx := intAbs(x1 - x0)
+	dy := -intAbs(y1 - y0)
+	var sx, sy int
+	if x0 < x1 {
+		sx = 1
+	} else {
+		sx = -1
+	}
+	if y0 < y1 {
+		sy = 1
+	} else {
+		sy = -1
+	}
+	err := dx + dy
+
+	var e2 int
+	for {
+		f(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 = 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Call f on each pixel of the line from (x0, y0) to (x1, y1) inside clip,
+// in cartesian coordinates.
+//
+// The pixels are the ones of linePixels, found row by row without walking the whole line:
+// with dx and dy the sizes of the line, the pixel a columns and b rows from the start
+// is on the line if 2*(dx*b - dy*a) is within max(dx, dy) of 0,
+// with the ties broken like in the Bresenham walk.
+func linePixelsIn(x0, y0, x1, y1 int, clip image.Rectangle, f func(x, y int)) {
+	// the columns and rows of the line inside clip, counted from the start
+	aMin, aMax := clipSpan(x0, x1, clip.Min.X, clip.Max.X-1)
+	bMin, bMax := clipSpan(y0, y1, clip.Min.Y, clip.Max.Y-1)
+	sx, sy := 1, 1
+	if x1 < x0 {
+		sx = -1
+	}
+	if y1 < y0 {
+		sy = -1
+	}
+
+	// straight lines have all the pixels in the span
+	dx, dy := intAbs(x1-x0), intAbs(y1-y0)
+	if dx == 0 || dy == 0 {
+		for b := bMin; b <= bMax; b++ {
+			for a := aMin; a <= aMax; a++ {
+				f(x0+sx*a, y0+sy*b)
+			}
+		}
+		return
+	}
+
+	m := intMax(dx, dy)
+	for b := bMin; b <= bMax; b++ {
+		var lo, hi int
+		if dx >= dy {
+			// -m < 2*(dx*b - dy*a) <= m
+			lo = ceilDiv(2*dx*b-m, 2*dy)
+			hi = ceilDiv(2*dx*b+m, 2*dy) - 1
+		} else {
+			// -m <= 2*(dx*b - dy*a) < m
+			lo = floorDiv(2*dx*b-m, 2*dy) + 1
+			hi = floorDiv(2*dx*b+m, 2*dy)
+		}
+		for a := intMax(lo, aMin); a <= intMin(hi, aMax); a++ {
+			f(x0+sx*a, y0+sy*b)
+		}
+	}
+}
+
+// Get the steps from v0 towards v1 that are in [lo, hi].
+//
+// The span is empty, with min > max, if none is.
+func clipSpan(v0, v1, lo, hi int) (int, int) {
+	n := intAbs(v1 - v0)
+	if v1 >= v0 {
+		return intMax(0, lo-v0), intMin(n, hi-v0)
+	}
+	return intMax(0, v0-hi), intMin(n, v0-lo)
+}
+
+// Get how many pixels the square of a point of the requested size
+// extends before (left and below) and after the point, in cartesian coordinates.
+func penSquare(size int) (before, half int) {
+	// always draw at least one pixel
+	if size <= 1 {
+		return 0, 0
+	}
+	half = size / 2
+	before = half
+	// if the size is even, remove a pixel from the left/bottom
+	if size%2 == 0 {
+		before = half - 1
+	}
+	return before, half
+}
+
+// Draw a point on the image.
+func (r *raster) setPoint(x, y int, p PenStyle) {
+	// the y in the reference frame of the image
+	yr := r.height - y - 1
+
+	// always draw at least one pixel
+	if p.Size <= 1 {
+		r.paint(x, yr, p)
+		return
+	}
+
+	before, half := penSquare(p.Size)
+	// fill the square
+	for i := -before; i <= half; i++ {
+		for ii := -before; ii <= half; ii++ {
+			// yr-ii because before/half are in cartesian coord
+			// so we move to image coord by flipping the y axis
+			r.paint(x+i, yr-ii, p)
+		}
+	}
+}
+
+// Paint a pixel, in image coordinates, according to the pen mode.
+func (r *raster) paint(x, y int, p PenStyle) {
+	if r.seen != nil {
+		pt := image.Point{x, y}
+		if r.seen[pt] {
+			return
+		}
+		r.seen[pt] = true
+	}
+
+	switch p.Mode {
+	case ModeDraw:
+		r.blend(x, y, p)
+
+	case ModeErase:
+		r.img.Set(x, y, r.background(x, y))
+
+	case ModeXor:
+		d := color.RGBAModel.Convert(r.img.At(x, y)).(color.RGBA)
+		c := color.RGBAModel.Convert(p.Color).(color.RGBA)
+		d.R ^= c.R
+		d.G ^= c.G
+		d.B ^= c.B
+		// on a transparent background the alpha is flipped too,
+		// so that drawing twice cancels
+		if r.bg == nil {
+			d.A ^= c.A
+		}
+		r.img.Set(x, y, d)
+
+	case ModeBehind:
+		if sameColor(r.img.At(x, y), r.background(x, y)) {
+			r.blend(x, y, p)
+		}
+	}
+}
+
+// Blend the Pen color on a pixel.
+func (r *raster) blend(x, y int, p PenStyle) {
+	if p.Blend == BlendNormal {
+		r.img.Set(x, y, p.Color)
+		return
+	}
+	r.img.Set(x, y, blendColors(r.img.At(x, y), p.Color, p.Blend))
+}
+
+// Get the background color of a pixel.
+func (r *raster) background(x, y int) color.Color {
+	if r.bg == nil {
+		return color.Transparent
+	}
+	return r.bg.At(x, y)
+}
+
+// Check if two colors are the same.
+func sameColor(a, b color.Color) bool {
+	r0, g0, b0, a0 := a.RGBA()
+	r1, g1, b1, a1 := b.RGBA()
+	return r0 == r1 && g0 == g1 && b0 == b1 && a0 == a1
+}
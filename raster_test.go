@@ -0,0 +1,57 @@
+package turtle_test
+
+import (
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+func TestXorPath(t *testing.T) {
+	w := turtle.NewWorld(50, 50)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetSize(1)
+	td.SetMode(turtle.ModeXor)
+	td.SetPrecise(true)
+	td.SetPos(10, 10)
+	td.PenDown()
+	td.Forward(20)
+	td.Left(90)
+	td.Forward(20)
+
+	for _, p := range [][2]int{{10, 10}, {20, 10}, {30, 10}, {30, 20}} {
+		if !drawn(w, p[0], p[1]) {
+			t.Errorf("pixel %v of the path is not drawn", p)
+		}
+	}
+	// the end of the path is left for the next line
+	if drawn(w, 30, 30) {
+		t.Error("the last pixel of the path is drawn")
+	}
+
+	// drawing the path back cancels it
+	td.Left(90)
+	td.Left(90)
+	td.Forward(20)
+	td.Right(90)
+	td.Forward(20)
+	for _, p := range [][2]int{{20, 10}, {30, 10}, {30, 20}} {
+		if drawn(w, p[0], p[1]) {
+			t.Errorf("pixel %v is still drawn after going back", p)
+		}
+	}
+}
+
+func TestXorPoint(t *testing.T) {
+	w := turtle.NewWorld(10, 10)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetSize(1)
+	td.SetMode(turtle.ModeXor)
+	td.SetPos(5, 5)
+	td.PenDown()
+	td.Forward(0)
+	if !drawn(w, 5, 5) {
+		t.Error("the point is not drawn")
+	}
+}
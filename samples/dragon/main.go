@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Pitrified/go-turtle"
@@ -30,9 +31,7 @@ func dragonSingle(level int) {
 	td.PenDown()
 	td.SetColor(turtle.DarkOrange)
 
-	for i := range instructions {
-		td.DoInstruction(i)
-	}
+	turtle.Run(context.Background(), td, instructions)
 
 	outImgName := fmt.Sprintf("dragon_single_%02d_%d.png", level, imgRes)
 	w.SaveImage(outImgName)
@@ -184,8 +184,48 @@ func reset() {
 	_ = w.SaveImage("resetImageImage.png")
 }
 
+func layers() {
+	w := turtle.NewWorld(900, 600)
+	defer w.Close()
+
+	// guides at the bottom, annotations on top
+	_, _ = w.AddLayer("guides")
+	_, _ = w.AddLayer("curve")
+	_, _ = w.AddLayer("notes")
+
+	td := turtle.NewTurtleDraw(w)
+
+	// a faint grid
+	_ = td.SetLayer("guides")
+	td.SetColor(turtle.White)
+	td.SetSize(1)
+	for x := 0.0; x < 900; x += 50 {
+		td.PenUp()
+		td.SetPos(x, 0)
+		td.PenDown()
+		td.SetPos(x, 599)
+	}
+	_ = w.SetLayerOpacity("guides", 0.3)
+
+	// the main drawing
+	_ = td.SetLayer("curve")
+	circle(td)
+
+	// a squiggly annotation
+	_ = td.SetLayer("notes")
+	squiggly(td)
+
+	_ = w.SaveImage("layers_all.png")
+
+	// hide the annotations and export the curve alone
+	_ = w.SetLayerHidden("notes", true)
+	_ = w.SaveImage("layers_no_notes.png")
+	_ = w.SaveLayer("curve", "layers_curve.png")
+}
+
 func main() {
 	general()
 	constructor()
 	reset()
+	layers()
 }
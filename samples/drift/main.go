@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/fractal"
+)
+
+// Distance of the turtle from the nearest point of the integer lattice.
+func latticeDrift(t *turtle.Turtle) float64 {
+	return math.Hypot(t.X-math.Round(t.X), t.Y-math.Round(t.Y))
+}
+
+// Walk a Hilbert curve, measuring the largest drift off the lattice.
+func hilbert(level int, precise bool) (float64, int) {
+	instructions := make(chan turtle.Instruction)
+	go fractal.GenerateHilbert(level, instructions, 1)
+
+	t := turtle.New()
+	t.SetPrecise(precise)
+	drift := 0.0
+	for i := range instructions {
+		t.DoInstruction(i)
+		drift = math.Max(drift, latticeDrift(t))
+	}
+	return drift, t.Stats().Moves
+}
+
+// Walk around a regular polygon many times, measuring how far from the start it ends.
+func polygon(sides, laps int, precise bool) float64 {
+	t := turtle.New()
+	t.SetPrecise(precise)
+	for i := 0; i < sides*laps; i++ {
+		t.Forward(1)
+		t.Left(360 / float64(sides))
+	}
+	return math.Hypot(t.X, t.Y)
+}
+
+func main() {
+	level := flag.Int("l", 10, "recursion level of the Hilbert curve")
+	laps := flag.Int("n", 100000, "laps around the polygons")
+	flag.Parse()
+
+	for _, precise := range []bool{false, true} {
+		fmt.Printf("precise: %v\n", precise)
+		drift, moves := hilbert(*level, precise)
+		fmt.Printf("  hilbert level %d, %d moves: max drift %g\n", *level, moves, drift)
+		for _, sides := range []int{3, 4, 6, 7} {
+			d := polygon(sides, *laps, precise)
+			fmt.Printf("  %d-gon, %d laps: %g from the start\n", sides, *laps, d)
+		}
+	}
+}
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"math"
+	"os"
 
 	"github.com/Pitrified/go-turtle"
 	"github.com/Pitrified/go-turtle/fractal"
 )
 
-func drawFractals(which, imgShape string, level int) {
+func drawFractals(which, imgShape string, level, rosette int, fit, text, opt, cache bool) {
 
 	var imgWidth, imgHeight float64
 	var startX, startY, startD float64
@@ -21,10 +24,22 @@ func drawFractals(which, imgShape string, level int) {
 	case "1200":
 		imgWidth = 1200
 		imgHeight = 1200
+	case "unbounded":
+		// the image grows to contain the drawing, which has nothing to fit:
+		// lay the fractal out like in a square image
+		if fit {
+			fmt.Println("An unbounded image already contains the whole fractal, do not fit it.")
+			return
+		}
+		imgWidth = 1200
+		imgHeight = 1200
+	default:
+		fmt.Println("Unknown image shape:", imgShape)
+		return
 	}
 
-	// receive the instructions here
-	instructions := make(chan turtle.Instruction)
+	// the generator will produce instructions on a channel
+	var generate func(chan<- turtle.Instruction)
 
 	switch which {
 
@@ -39,8 +54,7 @@ func drawFractals(which, imgShape string, level int) {
 		startY = hp
 		startD = 0
 
-		// will produce instructions on the channel
-		go fractal.GenerateHilbert(level, instructions, segLen)
+		generate = func(c chan<- turtle.Instruction) { fractal.GenerateHilbert(level, c, segLen) }
 
 	case "dragon":
 		// type 1: start at the center and spiral
@@ -50,7 +64,7 @@ func drawFractals(which, imgShape string, level int) {
 		// type 2 could rotate by 45 deg per level,
 		// to keep the main design fixed
 		// and reduce the length by a factor of sqrt(2)
-		go fractal.GenerateDragon(level, instructions, segLen)
+		generate = func(c chan<- turtle.Instruction) { fractal.GenerateDragon(level, c, segLen) }
 
 	case "sierpTri":
 		pad := 80.0
@@ -59,7 +73,7 @@ func drawFractals(which, imgShape string, level int) {
 		startY = (imgHeight - (imgHeight-pad)*math.Sin(math.Pi/3)) / 2
 		startD = 180.0
 		segLen := (imgHeight - pad) / math.Exp2(float64(level))
-		go fractal.GenerateSierpinskiTriangle(level, instructions, segLen)
+		generate = func(c chan<- turtle.Instruction) { fractal.GenerateSierpinskiTriangle(level, c, segLen) }
 
 	case "sierpArrow":
 		pad := 80.0
@@ -70,12 +84,48 @@ func drawFractals(which, imgShape string, level int) {
 			startD = 60.0
 		}
 		segLen := (imgHeight - pad) / math.Exp2(float64(level))
-		go fractal.GenerateSierpinskiArrowhead(level, instructions, segLen)
+		generate = func(c chan<- turtle.Instruction) { fractal.GenerateSierpinskiArrowhead(level, c, segLen) }
+
+	case "plant":
+		// grow from the bottom, leaning to the right
+		pad := 80.0
+		startX = imgWidth / 4
+		startY = pad / 2
+		startD = 65.0
+		segLen := 0.3 * (imgHeight - pad) / math.Exp2(float64(level))
+		generate = func(c chan<- turtle.Instruction) { fractal.GeneratePlant(level, c, segLen) }
+
+	default:
+		fmt.Println("Unknown fractal:", which)
+		return
+	}
 
+	// receive the instructions here, from the cache if there is one
+	instructions := make(chan turtle.Instruction)
+	if cache {
+		cached, err := openCache(fmt.Sprintf("%s_%02d_%s.trtl", which, level, imgShape), generate)
+		if err != nil {
+			fmt.Println("Could not use the cache:", err)
+			return
+		}
+		defer cached.Close()
+		go func() {
+			if err := turtle.NewDecoder(cached).DecodeAll(instructions); err != nil {
+				fmt.Println("Could not read the cache:", err)
+			}
+		}()
+	} else {
+		go generate(instructions)
 	}
 
 	// create a new world to draw in
-	w := turtle.NewWorld(int(imgWidth), int(imgHeight))
+	// an unbounded world grows to contain the whole fractal
+	var w *turtle.World
+	if imgShape == "unbounded" {
+		w = turtle.NewWorldUnbounded(turtle.SoftBlack, 40)
+	} else {
+		w = turtle.NewWorld(int(imgWidth), int(imgHeight))
+	}
 
 	// create and setup a turtle in the right place
 	td := turtle.NewTurtleDraw(w)
@@ -84,15 +134,103 @@ func drawFractals(which, imgShape string, level int) {
 	td.PenDown()
 	td.SetColor(turtle.DarkOrange)
 
+	// record the lines instead of drawing them directly
+	if fit {
+		w.StartRecording()
+	}
+
+	// save the instructions as text too, for other tools
+	var exec turtle.Executor = td
+	if text {
+		f, err := os.Create(fmt.Sprintf("%s_%02d.txt", which, level))
+		if err != nil {
+			fmt.Println("Could not save the instructions:", err)
+			return
+		}
+		defer f.Close()
+		iw := turtle.NewInstructionWriter(f)
+		defer iw.Flush()
+		exec = saver{td, iw}
+	}
+
+	// draw copies of the fractal around the start
+	var stream <-chan turtle.Instruction = instructions
+	if rosette > 1 {
+		stream = turtle.Repeat(fromStart(stream), rosette, 360/float64(rosette))
+	}
+
+	// shorten the stream just before drawing it, the image is the same
+	if opt {
+		stream = turtle.Optimize(stream, td)
+	}
+
 	// draw the fractal
-	for i := range instructions {
-		td.DoInstruction(i)
+	if _, err := turtle.Run(context.Background(), exec, stream); err != nil {
+		fmt.Println("Could not draw the fractal:", err)
+	}
+
+	// scale the recorded lines to fill the image
+	if fit {
+		w.StopRecording()
+		w.RenderFit(w.Lines, 40, turtle.FitContain)
 	}
 
 	outImgName := fmt.Sprintf("%s_%02d_%s.png", which, level, imgShape)
 	w.SaveImage(outImgName)
 }
 
+// Execute the instructions, writing them as text too.
+type saver struct {
+	turtle.Executor
+	iw *turtle.InstructionWriter
+}
+
+// Write the instruction, then execute it.
+func (s saver) DoInstruction(i turtle.Instruction) error {
+	if err := s.iw.Write(i); err != nil {
+		return err
+	}
+	return s.Executor.DoInstruction(i)
+}
+
+// Open the cached instructions of a fractal,
+// generating and saving them first if they are missing.
+func openCache(name string, generate func(chan<- turtle.Instruction)) (*os.File, error) {
+	if f, err := os.Open(name); err == nil {
+		return f, nil
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	instructions := make(chan turtle.Instruction)
+	go generate(instructions)
+	if _, err := turtle.NewEncoder(f).EncodeAll(instructions); err != nil {
+		f.Close()
+		os.Remove(name)
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Wrap the stream in Push and Pop, so that the turtle ends where it started.
+func fromStart(in <-chan turtle.Instruction) <-chan turtle.Instruction {
+	out := make(chan turtle.Instruction)
+	go func() {
+		out <- turtle.Instruction{Cmd: turtle.CmdPush}
+		for i := range in {
+			out <- i
+		}
+		out <- turtle.Instruction{Cmd: turtle.CmdPop}
+		close(out)
+	}()
+	return out
+}
+
 func getHilbertSegmentLen(level int, size float64) float64 {
 	return size / (math.Exp2(float64(level-1))*4 - 1)
 }
@@ -102,10 +240,31 @@ func getHilbertSegmentLen(level int, size float64) float64 {
 // go run main.go -f hilbert -l 7 -i 4K
 // go run main.go -f sierpArrow -l 7 -i 4K
 // go run main.go -f sierpTri -l 7 -i 4K
+// go run main.go -f plant -l 6 -i 4K
+//
+// The dragon does not care about the canvas, so fit it:
+// go run main.go -f dragon -l 16 -i 4K -fit
+//
+// Or let the image grow to contain it:
+// go run main.go -f dragon -l 14 -i unbounded
+//
+// Or draw a few copies in a circle:
+// go run main.go -f plant -l 5 -i 4K -rosette 6 -fit
+//
+// Cache the instructions in dragon_20_4K.trtl, the next runs replay them:
+// go run main.go -f dragon -l 20 -i 4K -fit -cache
+//
+// Save the instructions in dragon_10.txt too:
+// go run main.go -f dragon -l 10 -text
 func main() {
 	which := flag.String("f", "hilbert", "Type of fractal to generate.")
-	imgShape := flag.String("i", "4K", "Shape of the image to generate.")
+	imgShape := flag.String("i", "4K", "Shape of the image to generate: 4K, 1200 or unbounded.")
 	level := flag.Int("l", 4, "Recursion level to reach.")
+	fit := flag.Bool("fit", false, "Scale the drawing to fill the image.")
+	text := flag.Bool("text", false, "Save the instructions in a text file.")
+	opt := flag.Bool("opt", false, "Optimize the instructions.")
+	cache := flag.Bool("cache", false, "Replay the instructions from a binary file, saving it if missing.")
+	rosette := flag.Int("rosette", 1, "Number of copies to draw in a circle.")
 	flag.Parse()
-	drawFractals(*which, *imgShape, *level)
+	drawFractals(*which, *imgShape, *level, *rosette, *fit, *text, *opt, *cache)
 }
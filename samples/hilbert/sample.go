@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"image/color"
 	"math"
@@ -33,17 +34,7 @@ func hilbertSingle(level int) {
 	td.PenDown()
 	td.SetColor(color.RGBA{150, 75, 0, 255})
 
-	for i := range instructions {
-		td.DoInstruction(i)
-		// switch cmd {
-		// case "F":
-		// 	td.Forward(segLen)
-		// case "R":
-		// 	td.Right(90)
-		// case "L":
-		// 	td.Left(90)
-		// }
-	}
+	turtle.Run(context.Background(), td, instructions)
 
 	outImgName := fmt.Sprintf("hilbert_single_%02d_%d.png", level, imgRes)
 	w.SaveImage(outImgName)
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/fractal"
+)
+
+func main() {
+	// recursion level
+	level := 3
+
+	// receive the instructions here
+	instructions := make(chan turtle.Instruction)
+	go fractal.GenerateHilbert3D(level, instructions, 1)
+
+	// the curve fills a cube of side 2^level-1, from the origin to (side, -side, -side)
+	side := float64(int(1)<<level - 1)
+	target := turtle.Vec3{X: side / 2, Y: -side / 2, Z: -side / 2}
+	eye := target.Add(turtle.Vec3{X: side * 2.6, Y: -side * 1.4, Z: side * 1.7})
+	up := turtle.Vec3{Z: 1}
+
+	imgRes := 1080
+	w := turtle.NewWorld(imgRes, imgRes)
+	defer w.Close()
+
+	// look at the cube in perspective, fading the far side
+	cam := turtle.NewPerspectiveCamera(eye, target, up, 40, imgRes, imgRes)
+	td := turtle.NewTurtleDraw3D(w, cam)
+	dist := eye.Sub(target).Norm()
+	td.Shade = &turtle.DepthShade{Near: dist - side, Far: dist + side, Fog: turtle.SoftBlack}
+	td.SetColor(turtle.DarkOrange)
+	td.SetSize(4)
+	td.PenDown()
+
+	turtle.Run(context.Background(), td, instructions)
+	fmt.Println("TD:", td)
+
+	outImgName := fmt.Sprintf("hilbert3d_%02d_%d.png", level, imgRes)
+	err := w.SaveImage(outImgName)
+	if err != nil {
+		fmt.Println("Could not save the image: ", err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Pitrified/go-turtle"
+	"github.com/Pitrified/go-turtle/logo"
+)
+
+// Draw a tree, a recursive procedure with a base case.
+const tree = `
+; a branching tree
+TO tree :size :depth
+  IF :depth = 0 [ STOP ]
+  FD :size
+  LT 25 tree :size * 0.7 :depth - 1
+  RT 50 tree :size * 0.7 :depth - 1
+  LT 25
+  PU BK :size PD
+END
+
+; a ring of squares
+TO square :side
+  REPEAT 4 [ FD :side RT 90 ]
+END
+
+PU SETXY 300 100 SETH 90 PD
+SETPENSIZE 2
+tree 150 9
+
+PU SETXY 750 250 PD
+REPEAT 36 [ square 80 + REPCOUNT RT 10 ]
+`
+
+func main() {
+	src := flag.String("f", "", "Logo program to run, the sample one if empty.")
+	out := flag.String("o", "logo.png", "Image to save.")
+	flag.Parse()
+
+	program := tree
+	if *src != "" {
+		b, err := ioutil.ReadFile(*src)
+		if err != nil {
+			fmt.Println("Could not read the program:", err)
+			return
+		}
+		program = string(b)
+	}
+
+	w := turtle.NewWorld(1200, 800)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetColor(turtle.DarkOrange)
+
+	if err := logo.Run(program, td); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := w.SaveImage(*out); err != nil {
+		fmt.Println("Could not save the image:", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Draw a branch and two smaller branches at its tip,
+// going back to the tip with Pop after each one.
+func branch(td *turtle.TurtleDraw, length float64, level int) {
+	td.SetSize(level + 1)
+	td.Forward(length)
+	if level == 0 {
+		return
+	}
+
+	td.Push()
+	td.Left(25)
+	branch(td, length*0.75, level-1)
+	if err := td.Pop(); err != nil {
+		fmt.Println("Unbalanced stack:", err)
+	}
+
+	td.Push()
+	td.Right(35)
+	branch(td, length*0.7, level-1)
+	if err := td.Pop(); err != nil {
+		fmt.Println("Unbalanced stack:", err)
+	}
+}
+
+func main() {
+	w := turtle.NewWorld(1000, 800)
+	defer w.Close()
+
+	td := turtle.NewTurtleDraw(w)
+	td.SetPos(500, 20)
+	td.SetHeading(turtle.North)
+	td.SetColor(turtle.DarkOrange)
+	td.PenDown()
+
+	branch(td, 180, 9)
+
+	err := w.SaveImage("tree.png")
+	if err != nil {
+		fmt.Println("Could not save the image: ", err)
+	}
+}
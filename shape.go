@@ -0,0 +1,232 @@
+package turtle
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Name of the layer where the stamps are drawn.
+const StampLayer = "stamps"
+
+// Error returned when clearing a missing stamp.
+var ErrStampMissing = errors.New("turtle: stamp does not exist")
+
+// A polygon drawn by Stamp, in the frame of the turtle:
+// the X axis points forward, the Y axis to the left.
+type Shape []Point
+
+// Standard shapes.
+var (
+	ShapeArrow   = Shape{{10, 0}, {-10, 10}, {-10, -10}}
+	ShapeClassic = Shape{{0, 0}, {-9, 5}, {-7, 0}, {-9, -5}}
+	ShapeTurtle  = Shape{
+		{16, 0}, {14, 2}, {10, 1}, {7, 4}, {9, 7}, {8, 9}, {5, 6}, {1, 7},
+		{-3, 5}, {-6, 8}, {-8, 6}, {-5, 4}, {-7, 0}, {-5, -4}, {-8, -6}, {-6, -8},
+		{-3, -5}, {1, -7}, {5, -6}, {8, -9}, {9, -7}, {7, -4}, {10, -1}, {14, -2},
+	}
+	ShapeCircle = NewShapeCircle(10, 24)
+)
+
+// Create a circular Shape of radius r, approximated by a polygon with n sides.
+func NewShapeCircle(r float64, n int) Shape {
+	s := make(Shape, n)
+	for i := range s {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		s[i] = Point{r * math.Cos(a), r * math.Sin(a)}
+	}
+	return s
+}
+
+// A shape stamped on the World.
+type stamp struct {
+	id      int
+	td      *TurtleDraw
+	lines   []Line
+	cleared bool // Removed with ClearStamp.
+	undone  bool // Removed with Undo.
+}
+
+// Change the shape used by Stamp.
+func (td *TurtleDraw) SetShape(s Shape) {
+	td.do(func() { td.Shape = s })
+}
+
+// Stamp the shape of the turtle on the World,
+// at the current position, rotated to the current heading.
+//
+// The shape is filled with the FillColor of the Pen (or its Color if not set)
+// and outlined with the Pen Color.
+// Stamps are drawn on the StampLayer, created on top of the others if missing.
+// An unbounded World has no layers, and a recording World keeps the lines to fit them later:
+// in both cases the stamp is drawn (or recorded) on the background
+// and can not be cleared, the returned ID is 0.
+//
+// Returns the ID of the stamp, to clear it later.
+func (td *TurtleDraw) Stamp() int {
+	id := 0
+	td.do(func() { id = td.stamp() })
+	return id
+}
+
+// Stamp the shape of the turtle, returning the ID of the stamp.
+func (td *TurtleDraw) stamp() int {
+	w := td.W
+	layer := StampLayer
+	if w.chunks != nil || w.recording {
+		layer = ""
+	}
+
+	// place the shape in the World
+	pts := make([]Point, len(td.Shape))
+	for i, p := range td.Shape {
+		p = p.Rotate(td.Deg)
+		pts[i] = Point{td.X + p.X, td.Y + p.Y}
+	}
+
+	fill := td.FillColor
+	if fill == nil {
+		fill = td.Color
+	}
+	lines := fillPolygon(pts, fill, layer)
+	outline := PenStyle{Color: td.Color, Size: 1}
+	for i, p := range pts {
+		q := pts[(i+1)%len(pts)]
+		lines = append(lines, Line{p.X, p.Y, q.X, q.Y, outline, layer})
+	}
+
+	// the stamp is just more lines
+	if layer == "" {
+		for _, l := range lines {
+			td.drawLine(l)
+		}
+		return 0
+	}
+
+	// the layer and the stamps are changed on the listen goroutine,
+	// like the images, so that the turtles do not race
+	var s *stamp
+	w.run(func() {
+		if w.Layer(StampLayer) == nil {
+			// the World is bounded, the layer can not fail
+			w.AddLayer(StampLayer)
+		}
+		w.nextStamp++
+		s = &stamp{id: w.nextStamp, td: td, lines: lines}
+		w.stamps = append(w.stamps, s)
+	})
+	if td.op != nil {
+		td.op.stamp = s
+	}
+	for _, l := range lines {
+		w.sendLine(l, td.op)
+	}
+	return s.id
+}
+
+// Remove a stamp from the World.
+func (td *TurtleDraw) ClearStamp(id int) error {
+	w := td.W
+	if w.chunks != nil {
+		return ErrUnbounded
+	}
+	err := ErrStampMissing
+	w.run(func() {
+		unlock := w.lockHistory()
+		for _, s := range w.stamps {
+			if s.id == id && !s.cleared {
+				s.cleared = true
+				err = nil
+				break
+			}
+		}
+		unlock()
+		if err == nil {
+			w.redrawStamps()
+		}
+	})
+	return err
+}
+
+// Remove all the stamps of the turtle from the World.
+func (td *TurtleDraw) ClearStamps() {
+	w := td.W
+	w.run(func() {
+		unlock := w.lockHistory()
+		for _, s := range w.stamps {
+			if s.td == td {
+				s.cleared = true
+			}
+		}
+		unlock()
+		w.redrawStamps()
+	})
+}
+
+// Draw again the stamps that are still visible, from the listen goroutine.
+func (w *World) redrawStamps() {
+	l := w.Layer(StampLayer)
+	if l == nil {
+		return
+	}
+	// Undo and Redo change the stamps with the history locked
+	defer w.lockHistory()()
+
+	for i := range l.Image.Pix {
+		l.Image.Pix[i] = 0
+	}
+	kept := w.stamps[:0]
+	for _, s := range w.stamps {
+		if s.cleared {
+			continue
+		}
+		kept = append(kept, s)
+		if !s.undone {
+			w.drawLines(s.lines)
+		}
+	}
+	w.stamps = kept
+}
+
+// Fill a polygon with horizontal lines, one for each row of pixels.
+//
+// A pixel is filled if its center is inside the polygon.
+func fillPolygon(pts []Point, c color.Color, layer string) []Line {
+	if len(pts) < 3 {
+		return nil
+	}
+	minY, maxY := pts[0].Y, pts[0].Y
+	for _, p := range pts {
+		minY = math.Min(minY, p.Y)
+		maxY = math.Max(maxY, p.Y)
+	}
+
+	style := PenStyle{Color: c, Size: 1}
+	var lines []Line
+	var xs []float64
+	for y := math.Floor(minY); y <= maxY; y++ {
+		// the center of the pixels in this row
+		cy := y + 0.5
+
+		// find where the edges cross the row
+		xs = xs[:0]
+		for i, p := range pts {
+			q := pts[(i+1)%len(pts)]
+			if (p.Y <= cy) != (q.Y <= cy) {
+				xs = append(xs, p.X+(cy-p.Y)*(q.X-p.X)/(q.Y-p.Y))
+			}
+		}
+		sort.Float64s(xs)
+
+		// fill between pairs of crossings
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := math.Ceil(xs[i] - 0.5)
+			x1 := math.Floor(xs[i+1] - 0.5)
+			if x0 <= x1 {
+				lines = append(lines, Line{x0, y, x1, y, style, layer})
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,93 @@
+package turtle_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Check if the stamp layer has a pixel in cartesian coordinates (x, y).
+func stamped(w *turtle.World, x, y int) bool {
+	_, _, _, a := w.Layer(turtle.StampLayer).Image.At(x, w.Height-y-1).RGBA()
+	return a != 0
+}
+
+func TestStampClear(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	td.SetShape(turtle.ShapeCircle)
+	td.SetPos(20, 20)
+	first := td.Stamp()
+	td.SetPos(70, 70)
+	second := td.Stamp()
+	if first == 0 || second == 0 || first == second {
+		t.Fatalf("got the IDs %d and %d, want two different ones", first, second)
+	}
+	if !stamped(w, 20, 20) || !stamped(w, 70, 70) {
+		t.Fatal("the stamps were not drawn")
+	}
+
+	if err := td.ClearStamp(first); err != nil {
+		t.Fatal(err)
+	}
+	if stamped(w, 20, 20) || !stamped(w, 70, 70) {
+		t.Error("ClearStamp did not remove only the first stamp")
+	}
+	if err := td.ClearStamp(first); err != turtle.ErrStampMissing {
+		t.Errorf("got %v clearing twice, want ErrStampMissing", err)
+	}
+
+	td.ClearStamps()
+	if stamped(w, 70, 70) {
+		t.Error("ClearStamps did not remove the second stamp")
+	}
+}
+
+func TestStampRecording(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	td := turtle.NewTurtleDraw(w)
+	w.StartRecording()
+	if id := td.Stamp(); id != 0 {
+		t.Errorf("got ID %d while recording, want 0", id)
+	}
+	w.StopRecording()
+	if len(w.Lines) == 0 {
+		t.Error("the stamp was not recorded")
+	}
+	for _, l := range w.Lines {
+		if l.Layer != "" {
+			t.Fatalf("got a line on the layer %q, want the background", l.Layer)
+		}
+	}
+}
+
+func TestStampConcurrent(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	var wg sync.WaitGroup
+	ids := make([][]int, 4)
+	for k := range ids {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			td := turtle.NewTurtleDraw(w)
+			for i := 0; i < 20; i++ {
+				ids[k] = append(ids[k], td.Stamp())
+			}
+		}(k)
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool)
+	for _, list := range ids {
+		for _, id := range list {
+			if seen[id] {
+				t.Fatalf("got the ID %d twice", id)
+			}
+			seen[id] = true
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package turtle
+
+import (
+	"fmt"
+	"math"
+)
+
+// Statistics of the path followed by a Turtle.
+//
+// Distances are in World units, turns in degrees.
+type PathStats struct {
+	Distance float64 // Total distance travelled.
+	Drawn    float64 // Distance travelled with the Pen down.
+	Turned   float64 // Total rotation, in both directions.
+	Moves    int     // Number of moves.
+	Segments int     // Number of lines drawn.
+	Turns    int     // Number of rotations.
+
+	MinX, MinY float64 // Bounding box of the visited points,
+	MaxX, MaxY float64 // valid if Visited.
+	Visited    bool    // At least one point was visited.
+}
+
+// Get the distance travelled with the Pen up.
+func (s PathStats) PenUp() float64 {
+	return s.Distance - s.Drawn
+}
+
+// Get the size of the bounding box of the visited points.
+func (s PathStats) Size() (width, height float64) {
+	if !s.Visited {
+		return 0, 0
+	}
+	return s.MaxX - s.MinX, s.MaxY - s.MinY
+}
+
+// Estimate the time a plotter needs to follow the path,
+// moving at drawSpeed with the Pen down and at moveSpeed with the Pen up,
+// in units per second.
+//
+// The time to lift and lower the Pen is not considered.
+func (s PathStats) PlotTime(drawSpeed, moveSpeed float64) float64 {
+	t := 0.0
+	if s.Drawn > 0 {
+		t += s.Drawn / drawSpeed
+	}
+	if s.PenUp() > 0 {
+		t += s.PenUp() / moveSpeed
+	}
+	return t
+}
+
+var _ fmt.Stringer = PathStats{}
+
+// Write the statistics.
+//
+// Implements: fmt.Stringer
+func (s PathStats) String() string {
+	w, h := s.Size()
+	return fmt.Sprintf("distance %.4f (drawn %.4f) turned %.4f moves %d segments %d turns %d size %.4fx%.4f",
+		s.Distance, s.Drawn, s.Turned, s.Moves, s.Segments, s.Turns, w, h)
+}
+
+// Add a piece of path from (x0, y0) to (x1, y1).
+func (s *PathStats) path(x0, y0, x1, y1 float64, drawn bool) {
+	d := math.Hypot(x1-x0, y1-y0)
+	s.Distance += d
+	if drawn {
+		s.Drawn += d
+		s.Segments++
+	}
+	s.visit(x0, y0)
+	s.visit(x1, y1)
+}
+
+// Add a distance travelled, without the points visited.
+func (s *PathStats) skip(d float64, drawn bool) {
+	s.Distance += d
+	if drawn {
+		s.Drawn += d
+	}
+}
+
+// Add n rotations of deg degrees.
+func (s *PathStats) turnRepeated(deg, n float64) {
+	s.Turned += math.Abs(deg) * n
+	s.Turns += int(n)
+}
+
+// Add a rotation of deg degrees.
+func (s *PathStats) turn(deg float64) {
+	s.Turned += math.Abs(deg)
+	s.Turns++
+}
+
+// Grow the bounding box to contain (x, y).
+func (s *PathStats) visit(x, y float64) {
+	if !s.Visited {
+		s.MinX, s.MaxX = x, x
+		s.MinY, s.MaxY = y, y
+		s.Visited = true
+		return
+	}
+	s.MinX = math.Min(s.MinX, x)
+	s.MinY = math.Min(s.MinY, y)
+	s.MaxX = math.Max(s.MaxX, x)
+	s.MaxY = math.Max(s.MaxY, y)
+}
+
+// Get a snapshot of the path statistics.
+func (t *Turtle) Stats() PathStats {
+	return t.stats
+}
+
+// Reset the path statistics, starting from the current position.
+func (t *Turtle) ResetStats() {
+	t.stats = PathStats{}
+	t.stats.visit(t.X, t.Y)
+}
+
+// Record a move from (x0, y0) to the current position.
+func (t *Turtle) recordMove(x0, y0 float64) {
+	t.stats.Moves++
+	t.stats.path(x0, y0, t.X, t.Y, false)
+}
@@ -0,0 +1,73 @@
+package turtle_test
+
+import (
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+func TestStatsTurtle(t *testing.T) {
+	tu := turtle.New()
+	tu.SetPrecise(true)
+	tu.ResetStats()
+	tu.Forward(3)
+	tu.Left(90)
+	tu.Forward(4)
+	tu.SetPos(0, 0)
+	tu.Right(45)
+	// the shortest way from 45 to 300 is a turn right of 105
+	tu.SetHeading(300)
+
+	s := tu.Stats()
+	want := turtle.PathStats{
+		Distance: 3 + 4 + 5,
+		Turned:   90 + 45 + 105,
+		Moves:    3,
+		Turns:    3,
+		MaxX:     3,
+		MaxY:     4,
+		Visited:  true,
+	}
+	if s != want {
+		t.Errorf("got %v, want %v", s, want)
+	}
+	if w, h := s.Size(); w != 3 || h != 4 {
+		t.Errorf("got size %vx%v, want 3x4", w, h)
+	}
+
+	// the bounding box starts from the current position
+	tu.SetPos(-2, 1)
+	tu.ResetStats()
+	if s := tu.Stats(); !s.Visited || s.MinX != -2 || s.MaxX != -2 || s.MinY != 1 || s.Distance != 0 {
+		t.Errorf("got %v after the reset, want only the point (-2, 1)", s)
+	}
+}
+
+func TestStatsTurtleDraw(t *testing.T) {
+	w := turtle.NewWorld(50, 50)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 10, 10)
+	td.PenDown()
+	td.Forward(10)
+	td.Left(90)
+	td.Forward(10)
+	td.PenUp()
+	td.Forward(5)
+	td.Push()
+	td.Forward(20)
+	// the jump back of Pop is a move with the pen up
+	if err := td.Pop(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := td.Stats()
+	if s.Distance != 65 || s.Drawn != 20 || s.PenUp() != 45 {
+		t.Errorf("got distance %v drawn %v pen up %v, want 65, 20 and 45", s.Distance, s.Drawn, s.PenUp())
+	}
+	if s.Moves != 5 || s.Segments != 2 || s.Turns != 1 {
+		t.Errorf("got %d moves, %d segments and %d turns, want 5, 2 and 1", s.Moves, s.Segments, s.Turns)
+	}
+	if pt := s.PlotTime(10, 15); pt != 2+3 {
+		t.Errorf("got a plot time of %v, want 5", pt)
+	}
+}
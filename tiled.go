@@ -0,0 +1,245 @@
+package turtle
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Error returned when saving a TiledWorld without pixels.
+var ErrEmptyImage = errors.New("turtle: image has no pixels")
+
+// A huge image, rendered tile by tile from a list of lines.
+//
+// Only the lines are kept in memory, indexed by the tiles they touch,
+// and each tile is rasterized when needed.
+// Record the lines with a World (a tiny one is enough), then add them:
+//
+//	w := turtle.NewWorld(1, 1)
+//	w.StartRecording()
+//	// move the turtles around
+//	tw := turtle.NewTiledWorld(32768, 32768, 1024, turtle.SoftBlack)
+//	tw.AddLines(turtle.FitLines(w.Lines, tw.Width, tw.Height, 40, turtle.FitContain))
+//	err := tw.SaveImage("poster.png")
+//
+// The layer of the lines is ignored, everything is drawn on the same image.
+type TiledWorld struct {
+	Width, Height int
+	TileSize      int         // Side of the square tiles.
+	Background    color.Color // Color of the empty image.
+
+	lines []Line
+	tiles [][]int // Index of the lines touching each tile, row by row.
+	cols  int
+	rows  int
+}
+
+// Create a new TiledWorld of the requested size, tile size and background color.
+//
+// A tileSize that is not positive makes a single tile, as big as the image.
+func NewTiledWorld(width, height, tileSize int, c color.Color) *TiledWorld {
+	if tileSize <= 0 {
+		tileSize = width
+		if height > tileSize {
+			tileSize = height
+		}
+		if tileSize < 1 {
+			tileSize = 1
+		}
+	}
+	cols := (width + tileSize - 1) / tileSize
+	rows := (height + tileSize - 1) / tileSize
+	return &TiledWorld{
+		Width:      width,
+		Height:     height,
+		TileSize:   tileSize,
+		Background: c,
+		tiles:      make([][]int, cols*rows),
+		cols:       cols,
+		rows:       rows,
+	}
+}
+
+// Get the number of columns and rows of tiles.
+func (tw *TiledWorld) Tiles() (cols, rows int) {
+	return tw.cols, tw.rows
+}
+
+// Add a line to draw, in cartesian coordinates.
+func (tw *TiledWorld) AddLine(l Line) {
+	idx := len(tw.lines)
+	tw.lines = append(tw.lines, l)
+
+	// add the line to all the tiles its bounding box touches
+	b := lineRect(l, tw.Height)
+	c0, c1 := tw.tileSpan(b.Min.X, b.Max.X, tw.cols)
+	r0, r1 := tw.tileSpan(b.Min.Y, b.Max.Y, tw.rows)
+	for r := r0; r <= r1; r++ {
+		for c := c0; c <= c1; c++ {
+			i := r*tw.cols + c
+			tw.tiles[i] = append(tw.tiles[i], idx)
+		}
+	}
+}
+
+// Add a list of lines to draw.
+func (tw *TiledWorld) AddLines(lines []Line) {
+	for _, l := range lines {
+		tw.AddLine(l)
+	}
+}
+
+// Get the rectangle covered by a tile, in image coordinates.
+func (tw *TiledWorld) TileBounds(col, row int) image.Rectangle {
+	ts := tw.TileSize
+	r := image.Rect(col*ts, row*ts, (col+1)*ts, (row+1)*ts)
+	return r.Intersect(image.Rect(0, 0, tw.Width, tw.Height))
+}
+
+// Rasterize a single tile.
+//
+// The bounds of the returned image are in the coordinates of the full image.
+func (tw *TiledWorld) RenderTile(col, row int) *image.RGBA {
+	m := tw.newImage(tw.TileBounds(col, row))
+	r := raster{img: m, height: tw.Height, bg: &image.Uniform{tw.Background}}
+	for _, i := range tw.tiles[row*tw.cols+col] {
+		r.drawLine(tw.lines[i])
+	}
+	return m
+}
+
+// Save each tile in dir as tile_ROW_COL.png, rendering them one at a time.
+//
+// Returns ErrEmptyImage if the Width or the Height are not positive.
+func (tw *TiledWorld) SaveTiles(dir string) error {
+	if tw.Width <= 0 || tw.Height <= 0 {
+		return ErrEmptyImage
+	}
+	for row := 0; row < tw.rows; row++ {
+		for col := 0; col < tw.cols; col++ {
+			name := fmt.Sprintf("tile_%03d_%03d.png", row, col)
+			err := savePNG(filepath.Join(dir, name), tw.RenderTile(col, row))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Save the whole image as PNG.
+//
+// The image is streamed to the file one row of tiles at a time,
+// so only a band TileSize pixels high is kept in memory.
+// A PNG can not be empty: returns ErrEmptyImage, without creating the file,
+// if the Width or the Height are not positive.
+func (tw *TiledWorld) SaveImage(filePath string) error {
+	if tw.Width <= 0 || tw.Height <= 0 {
+		return ErrEmptyImage
+	}
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pw, err := newPNGStreamWriter(f, tw.Width, tw.Height)
+	if err != nil {
+		return err
+	}
+	row := make([]byte, 4*tw.Width)
+	for r := 0; r < tw.rows; r++ {
+		band := tw.renderBand(r)
+		b := band.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			start := band.PixOffset(b.Min.X, y)
+			nrgbaRow(row, band.Pix[start:start+4*b.Dx()])
+			if err := pw.WriteRow(row); err != nil {
+				return err
+			}
+		}
+	}
+	return pw.Close()
+}
+
+// Convert a row of premultiplied RGBA pixels to NRGBA, like the png package does.
+func nrgbaRow(dst, src []byte) {
+	for i := 0; i < len(src); i += 4 {
+		a := src[i+3]
+		if a == 0xff || a == 0 {
+			copy(dst[i:i+4], src[i:i+4])
+			continue
+		}
+		c := color.NRGBAModel.Convert(color.RGBA{src[i], src[i+1], src[i+2], a}).(color.NRGBA)
+		dst[i], dst[i+1], dst[i+2], dst[i+3] = c.R, c.G, c.B, c.A
+	}
+}
+
+// Rasterize a full row of tiles in a single image.
+//
+// The tiles are drawn in parallel, each on its own part of the band.
+func (tw *TiledWorld) renderBand(row int) *image.RGBA {
+	b := tw.TileBounds(0, row).Union(tw.TileBounds(tw.cols-1, row))
+	m := tw.newImage(b)
+	parallelDo(tw.cols, runtime.NumCPU(), func(col int) {
+		sub := m.SubImage(tw.TileBounds(col, row)).(*image.RGBA)
+		r := raster{img: sub, height: tw.Height, bg: &image.Uniform{tw.Background}}
+		for _, i := range tw.tiles[row*tw.cols+col] {
+			r.drawLine(tw.lines[i])
+		}
+	})
+	return m
+}
+
+// Create an image filled with the background color.
+func (tw *TiledWorld) newImage(b image.Rectangle) *image.RGBA {
+	m := image.NewRGBA(b)
+	draw.Draw(m, b, &image.Uniform{tw.Background}, b.Min, draw.Src)
+	return m
+}
+
+// Get the range of tiles touched by the pixels from lo to hi included.
+func (tw *TiledWorld) tileSpan(lo, hi, n int) (int, int) {
+	t0 := floorDiv(lo, tw.TileSize)
+	t1 := floorDiv(hi, tw.TileSize)
+	if t0 < 0 {
+		t0 = 0
+	}
+	if t1 > n-1 {
+		t1 = n - 1
+	}
+	// the line is outside the image, t0 > t1 and nothing is touched
+	return t0, t1
+}
+
+// Get the rectangle of pixels covered by a line, in image coordinates,
+// with Max included.
+func lineRect(l Line, height int) image.Rectangle {
+	x0, x1 := int(l.X0), int(l.X1)
+	y0, y1 := int(l.Y0), int(l.Y1)
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	before, half := 0, 0
+	if l.Style.Size > 1 {
+		half = l.Style.Size / 2
+		before = half
+		if l.Style.Size%2 == 0 {
+			before = half - 1
+		}
+	}
+	// flip the y axis, the top of the line in cartesian coord
+	// is the smallest y in image coord
+	return image.Rectangle{
+		Min: image.Point{x0 - before, height - y1 - 1 - half},
+		Max: image.Point{x1 + half, height - y0 - 1 + before},
+	}
+}
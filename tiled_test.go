@@ -0,0 +1,90 @@
+package turtle_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Decode a PNG file.
+func loadPNG(t *testing.T, name string) image.Image {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestTiledSaveImageTranslucent(t *testing.T) {
+	bg := color.NRGBA{200, 100, 50, 128}
+	lines := []turtle.Line{
+		{X0: 3, Y0: 5, X1: 90, Y1: 60, Style: turtle.PenStyle{Color: color.NRGBA{10, 200, 30, 255}, Size: 3}},
+		{X0: 0, Y0: 70, X1: 99, Y1: 2, Style: turtle.PenStyle{Color: color.NRGBA{250, 20, 90, 77}, Size: 1}},
+		{X0: 40, Y0: 0, X1: 40, Y1: 79, Style: turtle.PenStyle{Color: color.NRGBA{0, 0, 255, 200}, Size: 5}},
+	}
+	dir := t.TempDir()
+
+	w := turtle.NewWorldWithColor(100, 80, bg)
+	defer w.Close()
+	w.DrawLines(lines)
+	if err := w.SaveImage(filepath.Join(dir, "world.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	tw := turtle.NewTiledWorld(100, 80, 32, bg)
+	tw.AddLines(lines)
+	if err := tw.SaveImage(filepath.Join(dir, "tiled.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := loadPNG(t, filepath.Join(dir, "world.png"))
+	got := loadPNG(t, filepath.Join(dir, "tiled.png"))
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("got bounds %v, want %v", got.Bounds(), want.Bounds())
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x, y))
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			if g != w {
+				t.Fatalf("pixel (%d, %d): got %v, want %v", x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestTiledWorldTileSize(t *testing.T) {
+	tw := turtle.NewTiledWorld(100, 80, 0, turtle.SoftBlack)
+	if cols, rows := tw.Tiles(); cols != 1 || rows != 1 {
+		t.Errorf("got %dx%d tiles, want a single one", cols, rows)
+	}
+}
+
+func TestTiledWorldEmpty(t *testing.T) {
+	dir := t.TempDir()
+	for _, size := range [][2]int{{0, 80}, {100, 0}, {-3, 5}} {
+		tw := turtle.NewTiledWorld(size[0], size[1], 32, turtle.SoftBlack)
+		name := filepath.Join(dir, "empty.png")
+		if err := tw.SaveImage(name); err != turtle.ErrEmptyImage {
+			t.Errorf("%dx%d: got %v, want ErrEmptyImage", size[0], size[1], err)
+		}
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%dx%d: the file was created", size[0], size[1])
+		}
+		if err := tw.SaveTiles(dir); err != turtle.ErrEmptyImage {
+			t.Errorf("%dx%d: got %v from SaveTiles, want ErrEmptyImage", size[0], size[1], err)
+		}
+	}
+}
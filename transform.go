@@ -0,0 +1,309 @@
+package turtle
+
+import "image/color"
+
+// Scale the distances of an instruction stream by k.
+//
+// The moves, the radius of the circles and the positions set by SetPos are scaled,
+// the latter around the origin.
+//
+// The returned channel is closed when in is closed.
+func Scale(in <-chan Instruction, k float64) <-chan Instruction {
+	return transform(in, func(i Instruction) Instruction {
+		switch i.Cmd {
+		case CmdForward, CmdBackward, CmdCircle:
+			i.Amount *= k
+		case CmdSetPos:
+			i.X *= k
+			i.Y *= k
+		}
+		return i
+	})
+}
+
+// Mirror an instruction stream, swapping left and right.
+//
+// The relative moves are mirrored across the start heading of the turtle,
+// the positions set by SetPos and the headings set by SetHeading
+// across the X axis.
+//
+// The returned channel is closed when in is closed.
+func Mirror(in <-chan Instruction) <-chan Instruction {
+	return transform(in, func(i Instruction) Instruction {
+		switch i.Cmd {
+		case CmdLeft:
+			i.Cmd = CmdRight
+		case CmdRight:
+			i.Cmd = CmdLeft
+		case CmdRollLeft:
+			i.Cmd = CmdRollRight
+		case CmdRollRight:
+			i.Cmd = CmdRollLeft
+		case CmdCircle:
+			i.Amount = -i.Amount
+		case CmdSetPos:
+			i.Y = -i.Y
+		case CmdSetHeading:
+			i.Amount = -i.Amount
+		}
+		return i
+	})
+}
+
+// Rotate an instruction stream by deg degrees, counterclockwise.
+//
+// The stream starts with a turn left, so the relative moves
+// rotate around the start position of the turtle;
+// the positions set by SetPos rotate around the origin,
+// and the headings set by SetHeading are turned too.
+//
+// The returned channel is closed when in is closed.
+func Rotate(in <-chan Instruction, deg float64) <-chan Instruction {
+	out := make(chan Instruction)
+	go func() {
+		if i, ok := turnInstruction(deg); ok {
+			out <- i
+		}
+		ux, uy := unitVector(deg)
+		for i := range in {
+			switch i.Cmd {
+			case CmdSetPos:
+				i.X, i.Y = i.X*ux-i.Y*uy, i.X*uy+i.Y*ux
+			case CmdSetHeading:
+				i.Amount += deg
+			}
+			out <- i
+		}
+		close(out)
+	}()
+	return out
+}
+
+// Repeat an instruction stream n times, turning left by turn degrees
+// between the copies.
+//
+// With turns that split a full circle, like 12 copies and 30 degrees, the copies draw a rosette.
+// Each copy starts where the previous one ended:
+// to draw an open path around the same point, wrap it in Push and Pop.
+// The first copy is sent while it is received,
+// the stream is kept in memory for the others.
+//
+// The returned channel is closed when in is closed and all the copies are sent.
+func Repeat(in <-chan Instruction, n int, turn float64) <-chan Instruction {
+	out := make(chan Instruction)
+	go func() {
+		defer close(out)
+		if n < 1 {
+			drain(in)
+			return
+		}
+		var buf []Instruction
+		for i := range in {
+			buf = append(buf, i)
+			out <- i
+		}
+		for c := 1; c < n; c++ {
+			if i, ok := turnInstruction(turn); ok {
+				out <- i
+			}
+			for _, i := range buf {
+				out <- i
+			}
+		}
+	}()
+	return out
+}
+
+// Reverse an instruction stream, to walk the path back.
+//
+// A turtle starting where the original stream ends goes back along the same path,
+// in the opposite order, and ends where the original started.
+// The pen, color and size are set again so that each line is drawn
+// like in the original; until the first PenUp or PenDown the pen is assumed to be down,
+// and lines drawn before the first SetColor or SetSize
+// keep the last ones set in the reversed stream.
+// The jumps of Pop become moves with the pen up.
+//
+// The absolute commands SetPos and SetHeading can not be walked back and are dropped,
+// reverse only streams of relative moves.
+// The angles are assumed to be in degrees.
+// The whole stream is kept in memory, nothing is sent until in is closed.
+//
+// The returned channel is closed when all the instructions are sent.
+func Reverse(in <-chan Instruction) <-chan Instruction {
+	out := make(chan Instruction)
+	go func() {
+		r := reverser{t: New()}
+		r.t.SetPrecise(true)
+		r.style.pen = penDown
+		for i := range in {
+			r.add(i)
+		}
+		r.send(out)
+		close(out)
+	}()
+	return out
+}
+
+// State of the pen, as seen in an instruction stream.
+type penState byte
+
+const (
+	penUnknown penState = iota // Not set yet.
+	penDown
+	penUp
+)
+
+// Style of the lines, as seen in an instruction stream.
+type lineStyle struct {
+	pen      penState
+	color    color.Color
+	colorSet bool
+	size     float64
+	sizeSet  bool
+}
+
+// A step of a Reverse stream, already inverted.
+type reverseStep struct {
+	is    []Instruction // Instructions to send.
+	style lineStyle     // Style of the lines drawn.
+	jump  bool          // Move with the pen up.
+}
+
+// Accumulate the steps of a Reverse stream.
+type reverser struct {
+	t      *Turtle // Follows the path in its own frame, to measure the jumps.
+	style  lineStyle
+	styles []lineStyle // Styles saved by Push.
+	steps  []reverseStep
+}
+
+// Add an instruction to the stream.
+func (r *reverser) add(i Instruction) {
+	switch i.Cmd {
+	case CmdForward, CmdBackward:
+		r.draw(moveInstruction(-moveAmount(i)))
+	case CmdLeft:
+		r.turn(Instruction{Cmd: CmdRight, Amount: i.Amount})
+	case CmdRight:
+		r.turn(Instruction{Cmd: CmdLeft, Amount: i.Amount})
+	case CmdPitchUp:
+		r.turn(Instruction{Cmd: CmdPitchDown, Amount: i.Amount})
+	case CmdPitchDown:
+		r.turn(Instruction{Cmd: CmdPitchUp, Amount: i.Amount})
+	case CmdRollLeft:
+		r.turn(Instruction{Cmd: CmdRollRight, Amount: i.Amount})
+	case CmdRollRight:
+		r.turn(Instruction{Cmd: CmdRollLeft, Amount: i.Amount})
+	case CmdCircle:
+		extent := i.Extent
+		if extent == 0 {
+			extent = Degrees
+		}
+		r.draw(Instruction{Cmd: CmdCircle, Amount: i.Amount, Extent: -extent})
+
+	case CmdPenUp:
+		r.style.pen = penUp
+	case CmdPenDown:
+		r.style.pen = penDown
+	case CmdSetColor:
+		r.style.color, r.style.colorSet = i.Color, true
+	case CmdSetSize:
+		r.style.size, r.style.sizeSet = i.Amount, true
+
+	case CmdPush:
+		r.t.Push()
+		r.styles = append(r.styles, r.style)
+		return
+	case CmdPop:
+		before := r.t.pose()
+		if err := r.t.Pop(); err != nil {
+			return
+		}
+		r.style = r.styles[len(r.styles)-1]
+		r.styles = r.styles[:len(r.styles)-1]
+		// walk back from the restored pose to the one before the Pop
+		after := r.t.pose()
+		dx, dy := before.X-after.X, before.Y-after.Y
+		ux, uy := unitVector(after.Deg)
+		r.steps = append(r.steps, reverseStep{
+			is:   jumpInstructions(dx*ux+dy*uy, dy*ux-dx*uy, before.Deg-after.Deg),
+			jump: true,
+		})
+		return
+
+	default:
+		// SetPos and SetHeading are dropped
+		return
+	}
+	r.t.DoInstruction(i)
+}
+
+// Add a step that draws.
+func (r *reverser) draw(i Instruction) {
+	r.steps = append(r.steps, reverseStep{is: []Instruction{i}, style: r.style})
+}
+
+// Add a step that only turns.
+func (r *reverser) turn(i Instruction) {
+	r.steps = append(r.steps, reverseStep{is: []Instruction{i}})
+}
+
+// Send the steps in reverse order, setting the style of the lines when it changes.
+func (r *reverser) send(out chan<- Instruction) {
+	var cur lineStyle
+	for k := len(r.steps) - 1; k >= 0; k-- {
+		s := r.steps[k]
+		switch {
+		case s.jump:
+			if cur.pen != penUp {
+				out <- Instruction{Cmd: CmdPenUp}
+				cur.pen = penUp
+			}
+		case s.style.pen != penUnknown:
+			if s.style.pen != cur.pen {
+				if s.style.pen == penUp {
+					out <- Instruction{Cmd: CmdPenUp}
+				} else {
+					out <- Instruction{Cmd: CmdPenDown}
+				}
+				cur.pen = s.style.pen
+			}
+			if s.style.colorSet && (!cur.colorSet || !equalColor(s.style.color, cur.color)) {
+				out <- Instruction{Cmd: CmdSetColor, Color: s.style.color}
+				cur.color, cur.colorSet = s.style.color, true
+			}
+			if s.style.sizeSet && (!cur.sizeSet || s.style.size != cur.size) {
+				out <- Instruction{Cmd: CmdSetSize, Amount: s.style.size}
+				cur.size, cur.sizeSet = s.style.size, true
+			}
+		}
+		for _, i := range s.is {
+			out <- i
+		}
+	}
+}
+
+// Check if two colors, that can be nil, are the same.
+//
+// The colors are compared by value, their types might not be comparable.
+func equalColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return sameColor(a, b)
+}
+
+// Send the instructions of in, changed by f, on a new channel.
+//
+// The returned channel is closed when in is closed.
+func transform(in <-chan Instruction, f func(i Instruction) Instruction) <-chan Instruction {
+	out := make(chan Instruction)
+	go func() {
+		for i := range in {
+			out <- f(i)
+		}
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,59 @@
+package turtle_test
+
+import (
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+func TestRepeatTurnsBetweenCopies(t *testing.T) {
+	in := make(chan turtle.Instruction)
+	go fixed(turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10})(in)
+
+	var got []turtle.Instruction
+	for i := range turtle.Repeat(in, 3, 30) {
+		got = append(got, i)
+	}
+	f := turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10}
+	l := turtle.Instruction{Cmd: turtle.CmdLeft, Amount: 30}
+	want := []turtle.Instruction{f, l, f, l, f}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k := range want {
+		if got[k] != want[k] {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+}
+
+// A color that can not be compared with ==.
+type sliceColor []uint32
+
+func (c sliceColor) RGBA() (r, g, b, a uint32) {
+	return c[0], c[1], c[2], c[3]
+}
+
+func TestReverseColors(t *testing.T) {
+	red := sliceColor{0xffff, 0, 0, 0xffff}
+	blue := sliceColor{0, 0, 0xffff, 0xffff}
+	in := make(chan turtle.Instruction)
+	go fixed(
+		turtle.Instruction{Cmd: turtle.CmdPenDown},
+		turtle.Instruction{Cmd: turtle.CmdSetColor, Color: red},
+		turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10},
+		turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10},
+		turtle.Instruction{Cmd: turtle.CmdSetColor, Color: blue},
+		turtle.Instruction{Cmd: turtle.CmdForward, Amount: 10},
+	)(in)
+
+	var colors []turtle.Instruction
+	for i := range turtle.Reverse(in) {
+		if i.Cmd == turtle.CmdSetColor {
+			colors = append(colors, i)
+		}
+	}
+	if len(colors) != 2 || colors[0].Color.(sliceColor)[2] != 0xffff || colors[1].Color.(sliceColor)[0] != 0xffff {
+		t.Errorf("got the colors %v, want blue then red", colors)
+	}
+}
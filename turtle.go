@@ -1,16 +1,37 @@
 package turtle
 
 import (
+	"errors"
 	"fmt"
 	"math"
 )
 
+// Error returned when popping from an empty stack.
+var ErrStackEmpty = errors.New("turtle: pop from empty stack")
+
 // A minimal Turtle agent, moving on a cartesian plane.
 //
+// The angles received are measured in units of the full circle,
+// degrees by default, but Deg is always in degrees, in [0, 360).
+//
 // https://en.wikipedia.org/wiki/Turtle_graphics
 type Turtle struct {
 	X, Y float64 // Position.
 	Deg  float64 // Orientation in degrees.
+
+	stack      []pose  // Poses saved by Push.
+	fullCircle float64 // Units in a full turn, 0 means degrees.
+
+	stats PathStats // Odometer.
+
+	precise      bool    // Snap the headings and compensate the sums.
+	errX, errY   float64 // Rounding errors of the position, in precise mode.
+	lastX, lastY float64 // Position after the last precise move.
+}
+
+// Position and orientation of a Turtle.
+type pose struct {
+	X, Y, Deg float64
 }
 
 // Create a new Turtle.
@@ -20,9 +41,15 @@ func New() *Turtle {
 
 // Move the Turtle forward by dist.
 func (t *Turtle) Forward(dist float64) {
-	rad := Deg2rad(t.Deg)
-	t.X += dist * math.Cos(rad)
-	t.Y += dist * math.Sin(rad)
+	x0, y0 := t.X, t.Y
+	if t.precise {
+		t.forwardPrecise(dist)
+	} else {
+		rad := Deg2rad(t.Deg)
+		t.X += dist * math.Cos(rad)
+		t.Y += dist * math.Sin(rad)
+	}
+	t.recordMove(x0, y0)
 }
 
 // Move the Turtle backward by dist.
@@ -30,29 +57,120 @@ func (t *Turtle) Backward(dist float64) {
 	t.Forward(-dist)
 }
 
-// Rotate the Turtle counter clockwise by deg degrees.
+// Rotate the Turtle counter clockwise by deg degrees (or the current angle units).
 func (t *Turtle) Left(deg float64) {
-	t.Deg += deg
+	t.Deg = normDeg(t.Deg + t.toDeg(deg))
+	t.snap()
+	t.stats.turn(t.toDeg(deg))
 }
 
-// Rotate the Turtle clockwise by deg degrees.
+// Rotate the Turtle clockwise by deg degrees (or the current angle units).
 func (t *Turtle) Right(deg float64) {
-	t.Deg -= deg
+	t.Deg = normDeg(t.Deg - t.toDeg(deg))
+	t.snap()
+	t.stats.turn(t.toDeg(deg))
 }
 
 // Teleport the Turtle to (x, y).
 func (t *Turtle) SetPos(x, y float64) {
+	x0, y0 := t.X, t.Y
 	t.X = x
 	t.Y = y
+	t.recordMove(x0, y0)
 }
 
-// Orient the Turtle towards deg.
+// Orient the Turtle towards deg degrees (or the current angle units).
 func (t *Turtle) SetHeading(deg float64) {
-	t.Deg = deg
+	// count the shortest rotation to the new heading
+	d := normDeg(t.toDeg(deg) - t.Deg)
+	if d > 180 {
+		d -= 360
+	}
+	t.Deg = normDeg(t.toDeg(deg))
+	t.snap()
+	t.stats.turn(d)
+}
+
+// Get the orientation of the Turtle, in the current angle units.
+func (t *Turtle) Heading() float64 {
+	return t.fromDeg(t.Deg)
+}
+
+// Measure the angles in units, the size of a full turn.
+//
+// Use Degrees, Radians, Gradians or any number of divisions,
+// like Logo setfullcircle.
+// Values that are not positive restore degrees.
+func (t *Turtle) SetFullCircle(units float64) {
+	if units <= 0 {
+		units = Degrees
+	}
+	t.fullCircle = units
+}
+
+// Get the size of a full turn in the current angle units.
+func (t *Turtle) FullCircle() float64 {
+	if t.fullCircle == 0 {
+		return Degrees
+	}
+	return t.fullCircle
+}
+
+// Convert an angle from the current units to degrees.
+func (t *Turtle) toDeg(a float64) float64 {
+	if t.fullCircle == 0 || t.fullCircle == Degrees {
+		return a
+	}
+	return a * Degrees / t.fullCircle
+}
+
+// Convert an angle from degrees to the current units.
+func (t *Turtle) fromDeg(deg float64) float64 {
+	if t.fullCircle == 0 || t.fullCircle == Degrees {
+		return deg
+	}
+	return deg * t.fullCircle / Degrees
+}
+
+// Save the position and orientation on the stack.
+func (t *Turtle) Push() {
+	t.stack = append(t.stack, t.pose())
+}
+
+// Restore the last position and orientation saved on the stack.
+func (t *Turtle) Pop() error {
+	if len(t.stack) == 0 {
+		return ErrStackEmpty
+	}
+	x0, y0 := t.X, t.Y
+	t.setPose(t.stack[len(t.stack)-1])
+	t.stack = t.stack[:len(t.stack)-1]
+	t.recordMove(x0, y0)
+	return nil
+}
+
+// Get a copy of the Turtle, with its own stack.
+func (t *Turtle) clone() *Turtle {
+	c := *t
+	c.stack = append([]pose(nil), t.stack...)
+	return &c
+}
+
+// Get the current pose.
+func (t *Turtle) pose() pose {
+	return pose{t.X, t.Y, t.Deg}
+}
+
+// Set the current pose.
+func (t *Turtle) setPose(p pose) {
+	t.X, t.Y, t.Deg = p.X, p.Y, p.Deg
 }
 
 // Execute the received instruction.
-func (t *Turtle) DoInstruction(i Instruction) {
+//
+// The Turtle has no Pen: the Pen commands and the 3D rotations are ignored,
+// and CmdCircle just moves along the arc.
+func (t *Turtle) DoInstruction(i Instruction) error {
 	switch i.Cmd {
 	case CmdForward:
 		t.Forward(i.Amount)
@@ -62,7 +180,23 @@ func (t *Turtle) DoInstruction(i Instruction) {
 		t.Left(i.Amount)
 	case CmdRight:
 		t.Right(i.Amount)
+	case CmdSetPos:
+		t.SetPos(i.X, i.Y)
+	case CmdSetHeading:
+		t.SetHeading(i.Amount)
+	case CmdPush:
+		t.Push()
+	case CmdPop:
+		return t.Pop()
+	case CmdCircle:
+		t.Circle(i.Amount, i.Extent)
+	case CmdPitchUp, CmdPitchDown, CmdRollLeft, CmdRollRight,
+		CmdPenUp, CmdPenDown, CmdSetColor, CmdSetSize:
+		// ignored
+	default:
+		return ErrUnknownCmd
 	}
+	return nil
 }
 
 var _ fmt.Stringer = &Turtle{}
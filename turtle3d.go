@@ -0,0 +1,157 @@
+package turtle
+
+import "fmt"
+
+// A Turtle agent moving in 3D space.
+//
+// The orientation is given by three unit vectors:
+// the heading H, the left L and the up U directions.
+// The angles are in degrees.
+//
+// https://en.wikipedia.org/wiki/L-system (see "The Algorithmic Beauty of Plants")
+type Turtle3D struct {
+	Pos     Vec3 // Position.
+	H, L, U Vec3 // Orientation: heading, left and up.
+
+	stack []pose3D // Poses saved by Push.
+}
+
+// Position and orientation of a Turtle3D.
+type pose3D struct {
+	Pos     Vec3
+	H, L, U Vec3
+}
+
+// Create a new Turtle3D in the origin,
+// heading along X, with Y on the left and Z up.
+func NewTurtle3D() *Turtle3D {
+	return &Turtle3D{
+		H: Vec3{1, 0, 0},
+		L: Vec3{0, 1, 0},
+		U: Vec3{0, 0, 1},
+	}
+}
+
+// Move the Turtle3D forward by dist.
+func (t *Turtle3D) Forward(dist float64) {
+	t.Pos = t.Pos.Add(t.H.Scale(dist))
+}
+
+// Move the Turtle3D backward by dist.
+func (t *Turtle3D) Backward(dist float64) {
+	t.Forward(-dist)
+}
+
+// Rotate the Turtle3D around the up vector, to the left by deg degrees.
+func (t *Turtle3D) Yaw(deg float64) {
+	t.H = t.H.Rotate(t.U, deg)
+	t.orthonormalize()
+}
+
+// Rotate the Turtle3D around the left vector, nose up by deg degrees.
+func (t *Turtle3D) Pitch(deg float64) {
+	t.H = t.H.Rotate(t.L, -deg)
+	t.U = t.U.Rotate(t.L, -deg)
+	t.orthonormalize()
+}
+
+// Rotate the Turtle3D around the heading, to the right by deg degrees.
+func (t *Turtle3D) Roll(deg float64) {
+	t.U = t.U.Rotate(t.H, deg)
+	t.orthonormalize()
+}
+
+// Turn to the left by deg degrees.
+func (t *Turtle3D) Left(deg float64) {
+	t.Yaw(deg)
+}
+
+// Turn to the right by deg degrees.
+func (t *Turtle3D) Right(deg float64) {
+	t.Yaw(-deg)
+}
+
+// Teleport the Turtle3D to p.
+func (t *Turtle3D) SetPos(p Vec3) {
+	t.Pos = p
+}
+
+// Save the position and orientation on the stack.
+func (t *Turtle3D) Push() {
+	t.stack = append(t.stack, t.pose())
+}
+
+// Restore the last position and orientation saved on the stack.
+func (t *Turtle3D) Pop() error {
+	if len(t.stack) == 0 {
+		return ErrStackEmpty
+	}
+	t.setPose(t.stack[len(t.stack)-1])
+	t.stack = t.stack[:len(t.stack)-1]
+	return nil
+}
+
+// Get the current pose.
+func (t *Turtle3D) pose() pose3D {
+	return pose3D{t.Pos, t.H, t.L, t.U}
+}
+
+// Set the current pose.
+func (t *Turtle3D) setPose(p pose3D) {
+	t.Pos, t.H, t.L, t.U = p.Pos, p.H, p.L, p.U
+}
+
+// Execute the received instruction.
+//
+// Left and Right turn around the up vector.
+// The Pen commands are ignored,
+// the commands of the 2D turtles return ErrUnsupportedCmd.
+func (t *Turtle3D) DoInstruction(i Instruction) error {
+	switch i.Cmd {
+	case CmdForward:
+		t.Forward(i.Amount)
+	case CmdBackward:
+		t.Backward(i.Amount)
+	case CmdLeft:
+		t.Left(i.Amount)
+	case CmdRight:
+		t.Right(i.Amount)
+	case CmdPitchUp:
+		t.Pitch(i.Amount)
+	case CmdPitchDown:
+		t.Pitch(-i.Amount)
+	case CmdRollLeft:
+		t.Roll(-i.Amount)
+	case CmdRollRight:
+		t.Roll(i.Amount)
+	case CmdPush:
+		t.Push()
+	case CmdPop:
+		return t.Pop()
+	case CmdPenUp, CmdPenDown, CmdSetColor, CmdSetSize:
+		// ignored
+	case CmdSetPos, CmdSetHeading, CmdCircle:
+		return ErrUnsupportedCmd
+	default:
+		return ErrUnknownCmd
+	}
+	return nil
+}
+
+// Keep the orientation vectors orthogonal and of unit length,
+// rebuilding L and U from H,
+// so that the rounding errors do not pile up.
+func (t *Turtle3D) orthonormalize() {
+	t.H = t.H.Normalize()
+	t.L = t.U.Cross(t.H).Normalize()
+	t.U = t.H.Cross(t.L)
+}
+
+var _ fmt.Stringer = &Turtle3D{}
+
+// Write the Turtle3D state.
+//
+// Implements: fmt.Stringer
+func (t *Turtle3D) String() string {
+	return fmt.Sprintf("%s ^ %s", t.Pos, t.H)
+}
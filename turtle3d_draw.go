@@ -0,0 +1,150 @@
+package turtle
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// Fade the lines towards a color as they get farther from the camera.
+type DepthShade struct {
+	Near, Far float64     // Depth range of the fading.
+	Fog       color.Color // Color of the lines at depth Far and beyond.
+}
+
+// A drawing Turtle3D, projecting its lines on a World with a Camera.
+type TurtleDraw3D struct {
+	Turtle3D // Turtle agent to move around.
+	Pen      // Pen used when drawing.
+
+	W      *World      // World to draw on.
+	Layer  string      // Layer of the World to draw on, empty for the background.
+	Camera Camera      // Camera used to project the lines.
+	Shade  *DepthShade // Depth shading, nil to disable it.
+
+	stack []drawPose3D // Poses and Pens saved by Push.
+}
+
+// A pose of the Turtle3D, with the Pen.
+type drawPose3D struct {
+	pose pose3D
+	pen  Pen
+}
+
+// Create a new TurtleDraw3D, attached to the World w, seen from the Camera c.
+func NewTurtleDraw3D(w *World, c Camera) *TurtleDraw3D {
+	t := *NewTurtle3D()
+	p := *NewPen()
+	return &TurtleDraw3D{Turtle3D: t, Pen: p, W: w, Camera: c}
+}
+
+// Move the turtle forward and draw the line if the Pen is On.
+func (td *TurtleDraw3D) Forward(dist float64) {
+	p0 := td.Pos
+	td.Turtle3D.Forward(dist)
+	if td.On {
+		td.drawSegment(p0, td.Pos)
+	}
+}
+
+// Move the turtle backward and draw the line if the Pen is On.
+func (td *TurtleDraw3D) Backward(dist float64) {
+	td.Forward(-dist)
+}
+
+// Teleport the turtle to p and draw the line if the Pen is On.
+func (td *TurtleDraw3D) SetPos(p Vec3) {
+	p0 := td.Pos
+	td.Turtle3D.SetPos(p)
+	if td.On {
+		td.drawSegment(p0, td.Pos)
+	}
+}
+
+// Save the position, orientation and Pen on the stack.
+func (td *TurtleDraw3D) Push() {
+	td.stack = append(td.stack, drawPose3D{td.pose(), td.Pen})
+}
+
+// Restore the last position, orientation and Pen saved on the stack.
+//
+// The turtle jumps back without drawing.
+func (td *TurtleDraw3D) Pop() error {
+	if len(td.stack) == 0 {
+		return ErrStackEmpty
+	}
+	last := td.stack[len(td.stack)-1]
+	td.setPose(last.pose)
+	td.Pen = last.pen
+	td.stack = td.stack[:len(td.stack)-1]
+	return nil
+}
+
+// Execute the received instruction.
+func (td *TurtleDraw3D) DoInstruction(i Instruction) error {
+	switch i.Cmd {
+	case CmdForward:
+		td.Forward(i.Amount)
+	case CmdBackward:
+		td.Backward(i.Amount)
+	case CmdPenUp:
+		td.PenUp()
+	case CmdPenDown:
+		td.PenDown()
+	case CmdSetColor:
+		td.SetColor(i.Color)
+	case CmdSetSize:
+		td.SetSize(int(math.Round(i.Amount)))
+	case CmdPush:
+		td.Push()
+	case CmdPop:
+		return td.Pop()
+	default:
+		return td.Turtle3D.DoInstruction(i)
+	}
+	return nil
+}
+
+var _ fmt.Stringer = &TurtleDraw3D{}
+
+// Write the TurtleDraw3D state.
+//
+// Implements: fmt.Stringer
+func (td *TurtleDraw3D) String() string {
+	sT := td.Turtle3D.String()
+	sP := td.Pen.String()
+	return fmt.Sprintf("Turtle3D: %s Pen: %s", sT, sP)
+}
+
+// Project the segment and send the line to the World.
+func (td *TurtleDraw3D) drawSegment(a, b Vec3) {
+	p0, p1, d0, d1, ok := td.Camera.ProjectSegment(a, b)
+	if !ok {
+		return
+	}
+	style := td.Pen.Style()
+	if td.Shade != nil {
+		style.Color = td.Shade.apply(style.Color, (d0+d1)/2)
+	}
+	td.W.sendLine(Line{p0.X, p0.Y, p1.X, p1.Y, style, td.Layer}, nil)
+}
+
+// Fade the color c according to the depth d.
+func (ds *DepthShade) apply(c color.Color, d float64) color.Color {
+	t := 0.0
+	if ds.Far > ds.Near {
+		t = (d - ds.Near) / (ds.Far - ds.Near)
+	}
+	t = math.Max(0, math.Min(1, t))
+	return lerpColor(c, ds.Fog, t)
+}
+
+// Interpolate linearly between the colors a and b.
+func lerpColor(a, b color.Color, t float64) color.Color {
+	r0, g0, b0, a0 := a.RGBA()
+	r1, g1, b1, a1 := b.RGBA()
+	mix := func(x, y uint32) uint16 {
+		return uint16(float64(x)*(1-t) + float64(y)*t + 0.5)
+	}
+	return color.RGBA64{mix(r0, r1), mix(g0, g1), mix(b0, b1), mix(a0, a1)}
+}
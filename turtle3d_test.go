@@ -0,0 +1,117 @@
+package turtle_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+// Check if two vectors are the same, up to the rounding errors.
+func near3(a, b turtle.Vec3) bool {
+	return a.Sub(b).Norm() < 1e-9
+}
+
+func TestTurtle3DRotations(t *testing.T) {
+	cases := []struct {
+		name    string
+		rotate  func(t *turtle.Turtle3D)
+		h, l, u turtle.Vec3
+	}{
+		{"yaw", func(t *turtle.Turtle3D) { t.Yaw(90) }, turtle.Vec3{0, 1, 0}, turtle.Vec3{-1, 0, 0}, turtle.Vec3{0, 0, 1}},
+		{"pitch", func(t *turtle.Turtle3D) { t.Pitch(90) }, turtle.Vec3{0, 0, 1}, turtle.Vec3{0, 1, 0}, turtle.Vec3{-1, 0, 0}},
+		{"roll", func(t *turtle.Turtle3D) { t.Roll(90) }, turtle.Vec3{1, 0, 0}, turtle.Vec3{0, 0, 1}, turtle.Vec3{0, -1, 0}},
+	}
+	for _, c := range cases {
+		tu := turtle.NewTurtle3D()
+		c.rotate(tu)
+		if !near3(tu.H, c.h) || !near3(tu.L, c.l) || !near3(tu.U, c.u) {
+			t.Errorf("%s: got H %v L %v U %v, want H %v L %v U %v", c.name, tu.H, tu.L, tu.U, c.h, c.l, c.u)
+		}
+	}
+}
+
+func TestTurtle3DForward(t *testing.T) {
+	tu := turtle.NewTurtle3D()
+	tu.Pitch(90)
+	tu.Forward(5)
+	if !near3(tu.Pos, turtle.Vec3{0, 0, 5}) {
+		t.Errorf("got %v, want (0, 0, 5)", tu.Pos)
+	}
+}
+
+func TestTurtle3DPushPop(t *testing.T) {
+	tu := turtle.NewTurtle3D()
+	is := []turtle.Instruction{
+		{Cmd: turtle.CmdForward, Amount: 2},
+		{Cmd: turtle.CmdPush},
+		{Cmd: turtle.CmdRollLeft, Amount: 30},
+		{Cmd: turtle.CmdPitchUp, Amount: 45},
+		{Cmd: turtle.CmdForward, Amount: 5},
+		{Cmd: turtle.CmdPop},
+	}
+	for _, i := range is {
+		if err := tu.DoInstruction(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := turtle.NewTurtle3D()
+	want.Forward(2)
+	if tu.Pos != want.Pos || tu.H != want.H || tu.L != want.L || tu.U != want.U {
+		t.Errorf("got %v, want %v after the branch", tu, want)
+	}
+	if err := tu.Pop(); err != turtle.ErrStackEmpty {
+		t.Errorf("got %v popping an empty stack, want ErrStackEmpty", err)
+	}
+}
+
+func TestTurtleDraw3DPushPop(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	cam := turtle.NewOrthoCamera(turtle.Vec3{0, 0, 10}, turtle.Vec3{}, turtle.Vec3{0, 1, 0}, 1, 100, 100)
+	td := turtle.NewTurtleDraw3D(w, cam)
+	td.PenDown()
+	td.Push()
+	td.SetColor(turtle.Red)
+	td.PenUp()
+	td.Forward(5)
+	if err := td.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if td.Pos != (turtle.Vec3{}) || !td.On || td.Color != turtle.White {
+		t.Errorf("got %v, want the pose and Pen before Push", td)
+	}
+}
+
+func TestDepthShade(t *testing.T) {
+	w := turtle.NewWorld(100, 100)
+	defer w.Close()
+	// looking down from z = 10, the line on z = 0 is at depth 10
+	cam := turtle.NewOrthoCamera(turtle.Vec3{0, 0, 10}, turtle.Vec3{}, turtle.Vec3{0, 1, 0}, 1, 100, 100)
+	td := turtle.NewTurtleDraw3D(w, cam)
+	td.SetSize(1)
+	td.Shade = &turtle.DepthShade{Near: 0, Far: 20, Fog: turtle.Black}
+	td.PenDown()
+	td.Forward(10)
+	r, _, _, _ := w.Image.At(55, 49).RGBA()
+	if math.Abs(float64(r>>8)-127.5) > 1 {
+		t.Errorf("got red %d halfway to the fog, want 127 or 128", r>>8)
+	}
+}
+
+func TestTurtle3DUnsupported(t *testing.T) {
+	w := turtle.NewWorld(10, 10)
+	defer w.Close()
+	cam := turtle.NewOrthoCamera(turtle.Vec3{0, 0, 10}, turtle.Vec3{}, turtle.Vec3{0, 1, 0}, 1, 10, 10)
+	executors := map[string]turtle.Executor{
+		"Turtle3D":     turtle.NewTurtle3D(),
+		"TurtleDraw3D": turtle.NewTurtleDraw3D(w, cam),
+	}
+	for name, e := range executors {
+		for _, c := range []turtle.CmdType{turtle.CmdSetPos, turtle.CmdSetHeading, turtle.CmdCircle} {
+			if err := e.DoInstruction(turtle.Instruction{Cmd: c, Amount: 10}); err != turtle.ErrUnsupportedCmd {
+				t.Errorf("%s command %d: got %v, want ErrUnsupportedCmd", name, c, err)
+			}
+		}
+	}
+}
@@ -1,32 +1,43 @@
 package turtle
 
-import "fmt"
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
 
 // A drawing Turtle.
 type TurtleDraw struct {
 	Turtle // Turtle agent to move around.
 	Pen    // Pen used when drawing.
 
-	W *World // World to draw on.
+	W     *World // World to draw on.
+	Layer string // Layer of the World to draw on, empty for the background.
+	Shape Shape  // Shape drawn by Stamp.
+
+	Boundary BoundaryMode // What to do at the border of the World.
+	err      error        // Error reported by BoundaryStop.
+
+	stack []drawPose // Poses and Pens saved by Push.
+
+	op *operation // Operation being recorded in the World history.
 }
 
 // Create a new TurtleDraw, attached to the World w.
 func NewTurtleDraw(w *World) *TurtleDraw {
 	t := *New()
 	p := *NewPen()
-	td := &TurtleDraw{t, p, w}
+	td := &TurtleDraw{Turtle: t, Pen: p, W: w, Shape: ShapeClassic}
 	return td
 }
 
 // Move the turtle forward and draw the line if the Pen is On.
+//
+// At the border of the World, the Boundary mode is applied.
 func (td *TurtleDraw) Forward(dist float64) {
-	x0, y0 := td.X, td.Y
-	td.Turtle.Forward(dist)
-	x1, y1 := td.X, td.Y
-	line := Line{x0, y0, x1, y1, &td.Pen}
-	if td.On {
-		td.drawLine(line)
-	}
+	td.do(func() {
+		td.move(func() { td.Turtle.Forward(dist) })
+	})
 }
 
 // Move the turtle backward and draw the line if the Pen is On.
@@ -35,18 +46,169 @@ func (td *TurtleDraw) Backward(dist float64) {
 }
 
 // Teleport the Turtle to (x, y) and draw the line if the Pen is On.
+//
+// At the border of the World, the Boundary mode is applied.
 func (td *TurtleDraw) SetPos(x, y float64) {
-	x0, y0 := td.X, td.Y
-	td.Turtle.SetPos(x, y)
-	x1, y1 := td.X, td.Y
-	line := Line{x0, y0, x1, y1, &td.Pen}
-	if td.On {
-		td.drawLine(line)
+	td.do(func() {
+		td.move(func() { td.Turtle.SetPos(x, y) })
+	})
+}
+
+// Rotate the Turtle counter clockwise by deg degrees (or the current angle units).
+func (td *TurtleDraw) Left(deg float64) {
+	td.do(func() { td.Turtle.Left(deg) })
+}
+
+// Rotate the Turtle clockwise by deg degrees (or the current angle units).
+func (td *TurtleDraw) Right(deg float64) {
+	td.do(func() { td.Turtle.Right(deg) })
+}
+
+// Orient the Turtle towards deg degrees (or the current angle units).
+func (td *TurtleDraw) SetHeading(deg float64) {
+	td.do(func() { td.Turtle.SetHeading(deg) })
+}
+
+// Measure the angles in units, the size of a full turn.
+func (td *TurtleDraw) SetFullCircle(units float64) {
+	td.do(func() { td.Turtle.SetFullCircle(units) })
+}
+
+// Enable or disable the precise mode of the Turtle.
+func (td *TurtleDraw) SetPrecise(on bool) {
+	td.do(func() { td.Turtle.SetPrecise(on) })
+}
+
+// Start writing.
+func (td *TurtleDraw) PenDown() {
+	td.do(td.Pen.PenDown)
+}
+
+// Stop writing.
+func (td *TurtleDraw) PenUp() {
+	td.do(td.Pen.PenUp)
+}
+
+// Toggle the pen state.
+func (td *TurtleDraw) PenToggle() {
+	td.do(td.Pen.PenToggle)
+}
+
+// Change the Pen color.
+func (td *TurtleDraw) SetColor(c color.Color) {
+	td.do(func() { td.Pen.SetColor(c) })
+}
+
+// Change the Pen fill color.
+func (td *TurtleDraw) SetFillColor(c color.Color) {
+	td.do(func() { td.Pen.SetFillColor(c) })
+}
+
+// Change the Pen size.
+func (td *TurtleDraw) SetSize(s int) {
+	td.do(func() { td.Pen.SetSize(s) })
+}
+
+// Change the Pen mode.
+func (td *TurtleDraw) SetMode(m PenMode) {
+	td.do(func() { td.Pen.SetMode(m) })
+}
+
+// Change the Pen blend mode.
+func (td *TurtleDraw) SetBlend(b BlendMode) {
+	td.do(func() { td.Pen.SetBlend(b) })
+}
+
+// Orient the Turtle towards (x, y).
+func (td *TurtleDraw) FaceTowards(x, y float64) {
+	td.do(func() { td.Turtle.FaceTowards(x, y) })
+}
+
+// Move the Turtle to (x, y), keeping the heading, and draw the line if the Pen is On.
+func (td *TurtleDraw) Goto(x, y float64) {
+	td.SetPos(x, y)
+}
+
+// Move the Turtle by (dx, dy), keeping the heading, and draw the line if the Pen is On.
+func (td *TurtleDraw) Offset(dx, dy float64) {
+	td.SetPos(td.X+dx, td.Y+dy)
+}
+
+// Move the Turtle sideways to the left by dist, keeping the heading,
+// and draw the line if the Pen is On.
+func (td *TurtleDraw) StrafeLeft(dist float64) {
+	td.do(func() {
+		td.move(func() { td.Turtle.StrafeLeft(dist) })
+	})
+}
+
+// Move the Turtle sideways to the right by dist, keeping the heading,
+// and draw the line if the Pen is On.
+func (td *TurtleDraw) StrafeRight(dist float64) {
+	td.StrafeLeft(-dist)
+}
+
+// A pose of the Turtle, with the Pen.
+type drawPose struct {
+	pose pose
+	pen  Pen
+}
+
+// Save the position, orientation and Pen on the stack.
+func (td *TurtleDraw) Push() {
+	td.do(func() {
+		td.stack = append(td.stack, drawPose{td.pose(), td.Pen})
+	})
+}
+
+// Restore the last position, orientation and Pen saved on the stack.
+//
+// The turtle jumps back without drawing.
+func (td *TurtleDraw) Pop() error {
+	if len(td.stack) == 0 {
+		return ErrStackEmpty
+	}
+	td.do(func() {
+		x0, y0 := td.X, td.Y
+		last := td.stack[len(td.stack)-1]
+		td.setPose(last.pose)
+		td.recordMove(x0, y0)
+		td.Pen = last.pen
+		td.stack = td.stack[:len(td.stack)-1]
+	})
+	return nil
+}
+
+// Draw on the Layer name of the World, use an empty name for the background.
+func (td *TurtleDraw) SetLayer(name string) error {
+	if name != "" && td.W.Layer(name) == nil {
+		return ErrLayerMissing
 	}
+	td.do(func() { td.Layer = name })
+	return nil
 }
 
 // Execute the received instruction.
-func (td *TurtleDraw) DoInstruction(i Instruction) {
+//
+// The 3D rotations are ignored.
+// Returns the error of the command,
+// or ErrOutOfBounds if it reached the border with BoundaryStop.
+// Err keeps reporting the last border reached.
+func (td *TurtleDraw) DoInstruction(i Instruction) error {
+	prev := td.err
+	td.err = nil
+	err := td.doInstruction(i)
+	if err == nil {
+		err = td.err
+	}
+	if td.err == nil {
+		td.err = prev
+	}
+	return err
+}
+
+// Execute the received instruction, returning the error of the command.
+func (td *TurtleDraw) doInstruction(i Instruction) error {
 	switch i.Cmd {
 	case CmdForward:
 		td.Forward(i.Amount)
@@ -56,7 +218,32 @@ func (td *TurtleDraw) DoInstruction(i Instruction) {
 		td.Left(i.Amount)
 	case CmdRight:
 		td.Right(i.Amount)
+	case CmdPenUp:
+		td.PenUp()
+	case CmdPenDown:
+		td.PenDown()
+	case CmdSetColor:
+		td.SetColor(i.Color)
+	case CmdSetSize:
+		td.SetSize(int(math.Round(i.Amount)))
+	case CmdSetPos:
+		td.SetPos(i.X, i.Y)
+	case CmdSetHeading:
+		td.SetHeading(i.Amount)
+	case CmdPush:
+		td.Push()
+	case CmdPop:
+		if err := td.Pop(); err != nil {
+			return err
+		}
+	case CmdCircle:
+		td.Circle(i.Amount, i.Extent)
+	case CmdPitchUp, CmdPitchDown, CmdRollLeft, CmdRollRight:
+		// ignored
+	default:
+		return ErrUnknownCmd
 	}
+	return nil
 }
 
 var _ fmt.Stringer = &TurtleDraw{}
@@ -70,8 +257,19 @@ func (td *TurtleDraw) String() string {
 	return fmt.Sprintf("Turtle: %s Pen: %s", sT, sP)
 }
 
+// Move the Turtle with f, then draw the move applying the Boundary.
+//
+// The statistics count the path actually followed, not the one requested.
+func (td *TurtleDraw) move(f func()) {
+	x0, y0 := td.X, td.Y
+	stats := td.stats
+	f()
+	td.stats = stats
+	td.stats.Moves++
+	td.travel(x0, y0)
+}
+
 // Send the line to the world and wait for it to be drawn
 func (td *TurtleDraw) drawLine(l Line) {
-	td.W.DrawLineCh <- l
-	<-td.W.doneLineCh
+	td.W.sendLine(l, td.op)
 }
@@ -0,0 +1,70 @@
+package turtle_test
+
+import (
+	"testing"
+
+	"github.com/Pitrified/go-turtle"
+)
+
+func TestPushPop(t *testing.T) {
+	tu := turtle.New()
+	tu.SetPos(1, 2)
+	tu.Left(30)
+	tu.Push()
+	tu.Forward(10)
+	tu.Right(90)
+	tu.Push()
+	tu.SetPos(-5, 7)
+
+	if err := tu.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tu.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if tu.X != 1 || tu.Y != 2 || tu.Deg != 30 {
+		t.Errorf("got (%v, %v) heading %v, want (1, 2) heading 30", tu.X, tu.Y, tu.Deg)
+	}
+	if err := tu.Pop(); err != turtle.ErrStackEmpty {
+		t.Errorf("got %v popping the empty stack, want ErrStackEmpty", err)
+	}
+	if err := tu.DoInstruction(turtle.Instruction{Cmd: turtle.CmdPop}); err != turtle.ErrStackEmpty {
+		t.Errorf("got %v from CmdPop, want ErrStackEmpty", err)
+	}
+}
+
+func TestTurtleDrawPushPop(t *testing.T) {
+	w := turtle.NewWorld(50, 50)
+	defer w.Close()
+	td := newBounded(w, turtle.BoundaryNone, 10, 10)
+	td.PenDown()
+	td.Push()
+	td.SetColor(turtle.Red)
+	td.SetSize(3)
+	td.PenUp()
+	td.Forward(30)
+	td.Left(90)
+
+	// the jump back does not draw, even if the pen was down when pushed
+	if err := td.Pop(); err != nil {
+		t.Fatal(err)
+	}
+	if td.X != 10 || td.Y != 10 || td.Deg != 0 {
+		t.Errorf("got (%v, %v) heading %v, want (10, 10) heading 0", td.X, td.Y, td.Deg)
+	}
+	if !td.On || td.Color != turtle.White || td.Size != 1 {
+		t.Errorf("got pen on %t color %v size %d, want the pen pushed", td.On, td.Color, td.Size)
+	}
+	for x := 11; x < 40; x++ {
+		if drawn(w, x, 10) {
+			t.Fatalf("pixel (%d, 10) drawn by the pen up or by the jump back", x)
+		}
+	}
+
+	if err := td.Pop(); err != turtle.ErrStackEmpty {
+		t.Errorf("got %v popping the empty stack, want ErrStackEmpty", err)
+	}
+	if err := td.DoInstruction(turtle.Instruction{Cmd: turtle.CmdPop}); err != turtle.ErrStackEmpty {
+		t.Errorf("got %v from CmdPop, want ErrStackEmpty", err)
+	}
+}
@@ -0,0 +1,126 @@
+package turtle
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Side of the chunks of an unbounded World.
+const ChunkSize = 512
+
+// Error returned by the operations an unbounded World does not support,
+// like adding layers or clearing stamps.
+var ErrUnbounded = errors.New("turtle: operation not supported on an unbounded World")
+
+// Create a new unbounded World, with the requested background color.
+//
+// The image grows as needed, in chunks of ChunkSize pixels,
+// and is cropped to the drawn extent plus margin pixels when saved.
+// The origin of the image is at (0, 0), but there is no border to fall off.
+// Width and Height are zero, Image is nil: use Composite to get the image.
+func NewWorldUnbounded(c color.Color, margin int) *World {
+	w := NewWorldWithImage(image.NewRGBA(image.Rectangle{}))
+	w.Image = nil
+	w.chunks = newChunkImage(c)
+	w.Margin = margin
+	return w
+}
+
+// Check if the World is unbounded.
+func (w *World) Unbounded() bool {
+	return w.chunks != nil
+}
+
+// An image split in chunks, allocated when first drawn on.
+//
+// Implements draw.Image, with infinite bounds.
+type chunkImage struct {
+	bg     color.Color
+	chunks map[image.Point]*image.RGBA // Chunks by chunk coordinates.
+	used   image.Rectangle             // Pixels drawn on.
+}
+
+var _ draw.Image = &chunkImage{}
+
+// Create an empty chunkImage.
+func newChunkImage(bg color.Color) *chunkImage {
+	return &chunkImage{bg: bg, chunks: make(map[image.Point]*image.RGBA)}
+}
+
+// Implements: image.Image
+func (ci *chunkImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Implements: image.Image
+func (ci *chunkImage) Bounds() image.Rectangle {
+	return image.Rect(math.MinInt32, math.MinInt32, math.MaxInt32, math.MaxInt32)
+}
+
+// Implements: image.Image
+func (ci *chunkImage) At(x, y int) color.Color {
+	c, ok := ci.chunks[chunkOf(x, y)]
+	if !ok {
+		return ci.bg
+	}
+	return c.At(x, y)
+}
+
+// Implements: draw.Image
+func (ci *chunkImage) Set(x, y int, c color.Color) {
+	ci.chunk(x, y).Set(x, y, c)
+	ci.used = ci.used.Union(image.Rect(x, y, x+1, y+1))
+}
+
+// Get the chunk containing (x, y), creating it if needed.
+func (ci *chunkImage) chunk(x, y int) *image.RGBA {
+	p := chunkOf(x, y)
+	c, ok := ci.chunks[p]
+	if !ok {
+		r := image.Rect(p.X*ChunkSize, p.Y*ChunkSize, (p.X+1)*ChunkSize, (p.Y+1)*ChunkSize)
+		c = image.NewRGBA(r)
+		draw.Draw(c, r, &image.Uniform{ci.bg}, r.Min, draw.Src)
+		ci.chunks[p] = c
+	}
+	return c
+}
+
+// Copy the image to a new one, with bounds r.
+func (ci *chunkImage) crop(r image.Rectangle) *image.RGBA {
+	m := image.NewRGBA(r)
+	draw.Draw(m, r, &image.Uniform{ci.bg}, r.Min, draw.Src)
+	for _, c := range ci.chunks {
+		draw.Draw(m, c.Bounds(), c, c.Bounds().Min, draw.Src)
+	}
+	return m
+}
+
+// Copy the chunks.
+func (ci *chunkImage) clone() *chunkImage {
+	c := newChunkImage(ci.bg)
+	for p, m := range ci.chunks {
+		c.chunks[p] = cloneRGBA(m)
+	}
+	c.used = ci.used
+	return c
+}
+
+// Get the chunk coordinates of the pixel (x, y).
+func chunkOf(x, y int) image.Point {
+	return image.Point{floorDiv(x, ChunkSize), floorDiv(y, ChunkSize)}
+}
+
+// Get the drawn part of an unbounded image, with margin pixels around it.
+//
+// The bounds of the image are in image coordinates:
+// a pixel at cartesian (x, y) is at (x, -y-1).
+func (w *World) cropUnbounded() *image.RGBA {
+	r := w.chunks.used.Inset(-w.Margin)
+	if r.Empty() {
+		r = image.Rect(0, -1, 1, 0)
+	}
+	return w.chunks.crop(r)
+}
@@ -12,6 +12,19 @@ func Rad2deg(rad float64) float64 {
 	return rad / math.Pi * 180
 }
 
+// Normalize an angle in degrees to [0, 360).
+func normDeg(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	// a tiny negative angle rounds to 360
+	if deg >= 360 {
+		deg = 0
+	}
+	return deg
+}
+
 // int can be abs too!
 func intAbs(x int) int {
 	if x < 0 {
@@ -19,3 +32,33 @@ func intAbs(x int) int {
 	}
 	return x
 }
+
+// Integer division rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// Integer division rounding towards positive infinity.
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
+
+// The smaller of two ints.
+func intMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// The larger of two ints.
+func intMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
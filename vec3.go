@@ -0,0 +1,73 @@
+package turtle
+
+import (
+	"fmt"
+	"math"
+)
+
+// A vector in 3D space.
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Sum two vectors.
+func (v Vec3) Add(w Vec3) Vec3 {
+	return Vec3{v.X + w.X, v.Y + w.Y, v.Z + w.Z}
+}
+
+// Subtract w from v.
+func (v Vec3) Sub(w Vec3) Vec3 {
+	return Vec3{v.X - w.X, v.Y - w.Y, v.Z - w.Z}
+}
+
+// Multiply the vector by k.
+func (v Vec3) Scale(k float64) Vec3 {
+	return Vec3{v.X * k, v.Y * k, v.Z * k}
+}
+
+// Dot product.
+func (v Vec3) Dot(w Vec3) float64 {
+	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
+}
+
+// Cross product.
+func (v Vec3) Cross(w Vec3) Vec3 {
+	return Vec3{
+		v.Y*w.Z - v.Z*w.Y,
+		v.Z*w.X - v.X*w.Z,
+		v.X*w.Y - v.Y*w.X,
+	}
+}
+
+// Length of the vector.
+func (v Vec3) Norm() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// Get the vector with the same direction and unit length.
+func (v Vec3) Normalize() Vec3 {
+	n := v.Norm()
+	if n == 0 {
+		return v
+	}
+	return v.Scale(1 / n)
+}
+
+// Rotate the vector counter clockwise by deg degrees around the unit vector axis.
+//
+// https://en.wikipedia.org/wiki/Rodrigues%27_rotation_formula
+func (v Vec3) Rotate(axis Vec3, deg float64) Vec3 {
+	s, c := math.Sincos(Deg2rad(deg))
+	return v.Scale(c).
+		Add(axis.Cross(v).Scale(s)).
+		Add(axis.Scale(axis.Dot(v) * (1 - c)))
+}
+
+var _ fmt.Stringer = Vec3{}
+
+// Write the vector.
+//
+// Implements: fmt.Stringer
+func (v Vec3) String() string {
+	return fmt.Sprintf("(%9.4f, %9.4f, %9.4f)", v.X, v.Y, v.Z)
+}
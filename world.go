@@ -13,9 +13,26 @@ type World struct {
 	Image         *image.RGBA
 	Width, Height int
 
+	background image.Image // Restored by the eraser.
+
 	DrawLineCh chan Line
 	doneLineCh chan bool
 	closeCh    chan bool
+	opLineCh   chan opLine // Lines drawn by the turtles.
+	runCh      chan func() // Functions to run on the images.
+
+	Lines     []Line // Lines received while recording.
+	recording bool
+
+	layers []*Layer // Layers on top of Image, from the bottom.
+
+	history *history // Operations done by the turtles, nil if disabled.
+
+	stamps    []*stamp // Shapes stamped by the turtles.
+	nextStamp int
+
+	chunks *chunkImage // Image of an unbounded World, nil if bounded.
+	Margin int         // Margin around the drawing of an unbounded World.
 }
 
 // Create a new World of the requested size.
@@ -27,11 +44,18 @@ func NewWorld(width, height int) *World {
 func NewWorldWithColor(width, height int, c color.Color) *World {
 	m := image.NewRGBA(image.Rect(0, 0, width, height))
 	draw.Draw(m, m.Bounds(), &image.Uniform{c}, image.Point{0, 0}, draw.Src)
-	return NewWorldWithImage(m)
+	return newWorld(m, &image.Uniform{c})
 }
 
 // Create a new World attached to an image.
+//
+// A copy of the image is kept as background for the eraser.
 func NewWorldWithImage(m *image.RGBA) *World {
+	return newWorld(m, cloneRGBA(m))
+}
+
+// Create a new World attached to an image, with the background for the eraser.
+func newWorld(m *image.RGBA, bg image.Image) *World {
 	drawCh := make(chan Line)
 	doneCh := make(chan bool)
 	closeCh := make(chan bool)
@@ -39,9 +63,12 @@ func NewWorldWithImage(m *image.RGBA) *World {
 		Image:      m,
 		Width:      m.Bounds().Max.X,
 		Height:     m.Bounds().Max.Y,
+		background: bg,
 		DrawLineCh: drawCh,
 		doneLineCh: doneCh,
 		closeCh:    closeCh,
+		opLineCh:   make(chan opLine),
+		runCh:      make(chan func()),
 	}
 	// Start listening on w.DrawLineCh for lines to draw.
 	go w.listen()
@@ -49,7 +76,16 @@ func NewWorldWithImage(m *image.RGBA) *World {
 }
 
 // Reset the current image, keep the current size, default background color.
+//
+// An unbounded World stays unbounded.
 func (w *World) ResetImage() {
+	if w.chunks != nil {
+		w.chunks = newChunkImage(SoftBlack)
+		if w.history != nil {
+			w.EnableHistory()
+		}
+		return
+	}
 	w.ResetImageWithSizeColor(w.Width, w.Height, SoftBlack)
 }
 
@@ -62,43 +98,153 @@ func (w *World) ResetImageWithSize(width, height int) {
 func (w *World) ResetImageWithSizeColor(width, height int, c color.Color) {
 	m := image.NewRGBA(image.Rect(0, 0, width, height))
 	draw.Draw(m, m.Bounds(), &image.Uniform{c}, image.Point{0, 0}, draw.Src)
-	w.ResetImageWithImage(m)
+	w.resetImage(m, &image.Uniform{c})
 }
 
 // Reset the current image to the provided one.
+//
+// The layers are cleared and resized to match the new image.
+// An unbounded World becomes bounded.
+// A copy of the image is kept as background for the eraser.
 func (w *World) ResetImageWithImage(m *image.RGBA) {
+	w.resetImage(m, cloneRGBA(m))
+}
+
+// Reset the current image, with the background for the eraser.
+func (w *World) resetImage(m *image.RGBA, bg image.Image) {
+	w.chunks = nil
+	w.background = bg
 	w.Image = m
 	w.Width = m.Bounds().Max.X
 	w.Height = m.Bounds().Max.Y
+	for i, l := range w.layers {
+		w.layers[i] = newLayer(l.Name, w.Width, w.Height)
+		w.layers[i].Hidden = l.Hidden
+		w.layers[i].Opacity = l.Opacity
+	}
+	// start the history again from the new image
+	if w.history != nil {
+		w.EnableHistory()
+	}
 }
 
-// Save output
+// Save output, compositing the visible layers on the background.
 func (w *World) SaveImage(filePath string) error {
+	return savePNG(filePath, w.Composite())
+}
+
+// Save an image as PNG.
+func savePNG(filePath string, m image.Image) error {
 	f, err := os.Create(filePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	err = png.Encode(f, w.Image)
+	err = png.Encode(f, m)
 	return err
 }
 
+// Start recording: the lines received are stored in Lines instead of being drawn.
+//
+// Use RenderFit to draw them later, scaled to fill the image.
+func (w *World) StartRecording() {
+	w.recording = true
+}
+
+// Stop recording: the lines received are drawn again.
+//
+// The lines already recorded are kept in Lines.
+func (w *World) StopRecording() {
+	w.recording = false
+}
+
+// Draw the lines on the image, as they are.
+//
+// The lines are kept in the history, if enabled, and survive Undo.
+func (w *World) DrawLines(lines []Line) {
+	w.run(func() {
+		w.drawLines(lines)
+		w.remember(lines)
+	})
+}
+
+// Draw the lines on the image, from the listen goroutine.
+func (w *World) drawLines(lines []Line) {
+	for _, l := range lines {
+		w.drawLine(l)
+	}
+}
+
+// Keep in the history the lines drawn without a turtle.
+func (w *World) remember(lines []Line) {
+	if w.history == nil {
+		return
+	}
+	for _, l := range lines {
+		w.history.record(l, nil)
+	}
+}
+
+// Draw the lines on the image, scaled and centered to fill it,
+// leaving pad pixels free on each side.
+//
+// A typical use is to record a drawing and then fit it:
+//
+//	w.StartRecording()
+//	// move the turtles around
+//	w.StopRecording()
+//	w.RenderFit(w.Lines, 40, turtle.FitContain)
+//
+// The lines are rasterized in parallel, using all the CPUs,
+// and kept in the history, if enabled.
+func (w *World) RenderFit(lines []Line, pad float64, mode FitMode) {
+	w.DrawLinesParallel(FitLines(lines, w.Width, w.Height, pad, mode), 0)
+}
+
 // Close the world channels, and stop the listen goroutine.
 func (w *World) Close() {
 	w.closeCh <- true
 }
 
+// A line drawn by a turtle, with the operation that drew it.
+type opLine struct {
+	line Line
+	op   *operation // nil if the history is disabled, or for a Turtle3D.
+}
+
+// Send the line drawn by the operation op to the listen goroutine,
+// and wait for it to be drawn.
+func (w *World) sendLine(l Line, op *operation) {
+	w.opLineCh <- opLine{l, op}
+	<-w.doneLineCh
+}
+
+// Run f on the listen goroutine, that owns the images, and wait for it.
+func (w *World) run(f func()) {
+	w.runCh <- f
+	<-w.doneLineCh
+}
+
 // listen for draw commands on drawLineCh.
 func (w *World) listen() {
 	for {
 		select {
 
 		// draw the received line and wait for it to be drawn
-		// the pen inside is a reference, so if you change
-		// color/size before it is drawn it will change
-		// MAYBE not using a reference is better and clearer
+		// the line carries a copy of the pen style,
+		// so changing the pen later does not affect it
 		case line := <-w.DrawLineCh:
-			w.drawLine(line)
+			w.receiveLine(line, nil)
+			w.doneLineCh <- true
+
+		// the lines of the turtles are kept in the history with their operation
+		case ol := <-w.opLineCh:
+			w.receiveLine(ol.line, ol.op)
+			w.doneLineCh <- true
+
+		// the images are changed only on this goroutine
+		case f := <-w.runCh:
+			f()
 			w.doneLineCh <- true
 
 		// close the channels and exit the func
@@ -110,93 +256,32 @@ func (w *World) listen() {
 	}
 }
 
-// Draw a line on the image.
-func (w *World) drawLine(l Line) {
-	x0 := int(l.X0)
-	y0 := int(l.Y0)
-	x1 := int(l.X1)
-	y1 := int(l.Y1)
-
-	// line is vertical
-	if x0 == x1 {
-		if y0 > y1 {
-			y1, y0 = y0, y1
-		}
-		for i := y0; i <= y1; i++ {
-			w.setPoint(x0, i, l.p)
-		}
+// Record or draw a line received by listen, keeping it in the history.
+func (w *World) receiveLine(l Line, op *operation) {
+	if w.recording {
+		w.Lines = append(w.Lines, l)
 		return
 	}
-
-	// line is horizontal
-	if y0 == y1 {
-		if x0 > x1 {
-			x1, x0 = x0, x1
-		}
-		for i := x0; i <= x1; i++ {
-			w.setPoint(i, y0, l.p)
-		}
-		return
-	}
-
-	// line is diagonal, draw it with Bresenham algo
-	dx := intAbs(x1 - x0)
-	dy := -intAbs(y1 - y0)
-	var sx, sy int
-	if x0 < x1 {
-		sx = 1
-	} else {
-		sx = -1
-	}
-	if y0 < y1 {
-		sy = 1
-	} else {
-		sy = -1
-	}
-	err := dx + dy
-
-	var e2 int
-	for {
-		w.setPoint(x0, y0, l.p)
-		if x0 == x1 && y0 == y1 {
-			return
-		}
-		e2 = 2 * err
-		if e2 >= dy {
-			err += dy
-			x0 += sx
-		}
-		if e2 <= dx {
-			err += dx
-			y0 += sy
-		}
+	w.drawLine(l)
+	if w.history != nil {
+		w.history.record(l, op)
 	}
 }
 
-// Draw a point on the image.
-func (w *World) setPoint(x, y int, p *Pen) {
-	// the y in the reference frame of the image
-	yr := w.Height - y - 1
-
-	// always draw at least one pixel
-	if p.Size <= 1 {
-		w.Image.Set(x, yr, p.Color)
+// Draw a line on the image of its layer.
+//
+// Lines sent to a missing layer are dropped.
+func (w *World) drawLine(l Line) {
+	if w.chunks != nil {
+		// the y axis is flipped around 0
+		r := raster{img: w.chunks, bg: &image.Uniform{w.chunks.bg}}
+		r.drawLine(l)
 		return
 	}
-
-	half := p.Size / 2
-	before := half
-	// if the size is even, remove a pixel from the left/bottom
-	// in the cartesian coord
-	if p.Size%2 == 0 {
-		before = half - 1
-	}
-	// fill the square
-	for i := -before; i <= half; i++ {
-		for ii := -before; ii <= half; ii++ {
-			// yr-ii because before/half are in cartesian coord
-			// so we move to image coord by flipping the y axis
-			w.Image.Set(x+i, yr-ii, p.Color)
-		}
+	img := w.layerImage(l.Layer)
+	if img == nil {
+		return
 	}
+	r := raster{img: img, height: w.Height, bg: w.layerBackground(l.Layer)}
+	r.drawLine(l)
 }